@@ -1,24 +1,172 @@
 package scan
 
 import (
+	"math"
 	"reflect"
 
 	"github.com/pkg/errors"
 )
 
+var errUnsupported = errors.New("unsupported conversion")
+
 func copyAny(v any, to any) error {
-	if reflect.TypeOf(to).Kind() != reflect.Pointer {
+	if to == nil || reflect.TypeOf(to).Kind() != reflect.Pointer {
 		return errors.Errorf("cannot copy into non-pointer type %T", to)
 	}
+	if reflect.ValueOf(to).IsNil() {
+		return errors.Errorf("cannot copy into nil %T", to)
+	}
 	toElem := reflect.ValueOf(to).Elem()
 	if !toElem.CanSet() {
 		return errors.Errorf("cannot set %s", toElem.Type().String())
 	}
-	rv := reflect.ValueOf(v)
-	if !rv.CanConvert(toElem.Type()) {
-		return errors.Errorf("cannot convert %T to %s", v, toElem.Type().String())
+	return convertValue(v, toElem)
+}
+
+// convertValue sets rv to v. Numbers are converted, if they fit into the target type without overflow
+// or loss of precision (conversions from float64 to float32 are rounded). Strings convert to strings and byte slices only.
+// Pointer targets are allocated, interface targets are set, if v implements them.
+func convertValue(v any, rv reflect.Value) error {
+	if v == nil {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	vv := reflect.ValueOf(v)
+	if vv.Type().AssignableTo(rv.Type()) {
+		rv.Set(vv)
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Interface:
+		return errors.Wrapf(errUnsupported, "%T does not implement %s", v, rv.Type())
+	case reflect.Pointer:
+		elem := reflect.New(rv.Type().Elem())
+		if err := convertValue(v, elem.Elem()); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+	}
+
+	switch {
+	case isNumber(vv.Kind()) && isNumber(rv.Kind()):
+		return convertNumber(vv, rv)
+	case vv.Kind() == reflect.String && (rv.Kind() == reflect.String || isByteSlice(rv.Type())),
+		isByteSlice(vv.Type()) && rv.Kind() == reflect.String,
+		vv.Kind() == reflect.Bool && rv.Kind() == reflect.Bool:
+		rv.Set(vv.Convert(rv.Type()))
+		return nil
+	case vv.Kind() == reflect.Slice && rv.Kind() == reflect.Slice:
+		s := reflect.MakeSlice(rv.Type(), vv.Len(), vv.Len())
+		for i := 0; i < vv.Len(); i++ {
+			if err := convertValue(vv.Index(i).Interface(), s.Index(i)); err != nil {
+				return errors.Wrapf(err, "index %d", i)
+			}
+		}
+		rv.Set(s)
+		return nil
+	case vv.Kind() == rv.Kind() && vv.Type().ConvertibleTo(rv.Type()):
+		// types with the same underlying type
+		rv.Set(vv.Convert(rv.Type()))
+		return nil
+	}
+	return errors.Wrapf(errUnsupported, "cannot convert %T to %s", v, rv.Type())
+}
+
+func isByteSlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
+
+func isInt(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isNumber(k reflect.Kind) bool {
+	return isInt(k) || isUint(k) || k == reflect.Float32 || k == reflect.Float64
+}
+
+func convertNumber(vv reflect.Value, rv reflect.Value) error {
+	overflow := func() error {
+		return errors.Errorf("%v overflows %s", vv.Interface(), rv.Type())
+	}
+	switch {
+	case isInt(vv.Kind()):
+		i := vv.Int()
+		switch {
+		case isInt(rv.Kind()):
+			if rv.OverflowInt(i) {
+				return overflow()
+			}
+			rv.SetInt(i)
+		case isUint(rv.Kind()):
+			if i < 0 || rv.OverflowUint(uint64(i)) {
+				return overflow()
+			}
+			rv.SetUint(uint64(i))
+		default:
+			// round to the precision of the target type before comparing
+			f := reflect.ValueOf(float64(i)).Convert(rv.Type()).Float()
+			if int64(f) != i {
+				return errors.Errorf("%d cannot be represented exactly as %s", i, rv.Type())
+			}
+			rv.SetFloat(f)
+		}
+	case isUint(vv.Kind()):
+		u := vv.Uint()
+		switch {
+		case isInt(rv.Kind()):
+			if u > math.MaxInt64 || rv.OverflowInt(int64(u)) {
+				return overflow()
+			}
+			rv.SetInt(int64(u))
+		case isUint(rv.Kind()):
+			if rv.OverflowUint(u) {
+				return overflow()
+			}
+			rv.SetUint(u)
+		default:
+			f := reflect.ValueOf(float64(u)).Convert(rv.Type()).Float()
+			if uint64(f) != u {
+				return errors.Errorf("%d cannot be represented exactly as %s", u, rv.Type())
+			}
+			rv.SetFloat(f)
+		}
+	default:
+		f := vv.Float()
+		switch {
+		case isInt(rv.Kind()), isUint(rv.Kind()):
+			if math.IsNaN(f) || math.IsInf(f, 0) || f != math.Trunc(f) {
+				return errors.Errorf("%v is not an integer", f)
+			}
+			if isInt(rv.Kind()) {
+				if f < math.MinInt64 || f >= math.MaxInt64 || rv.OverflowInt(int64(f)) {
+					return overflow()
+				}
+				rv.SetInt(int64(f))
+			} else {
+				if f < 0 || f >= math.MaxUint64 || rv.OverflowUint(uint64(f)) {
+					return overflow()
+				}
+				rv.SetUint(uint64(f))
+			}
+		default:
+			if rv.OverflowFloat(f) {
+				return overflow()
+			}
+			rv.SetFloat(f)
+		}
 	}
-	crv := rv.Convert(toElem.Type())
-	toElem.Set(crv)
 	return nil
 }
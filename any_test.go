@@ -1,6 +1,8 @@
 package scan
 
 import (
+	"fmt"
+	"math"
 	"reflect"
 	"testing"
 )
@@ -50,6 +52,9 @@ func TestCopyAny(t *testing.T) {
 	assertEqual(t, 32.34, f)
 
 	err = copyAny(float64(32.34), &n)
+	noErrWhenErrExpected(t, err)
+
+	err = copyAny(float64(32), &n)
 	errWhenNoneExpected(t, err)
 	assertEqual(t, 32, n)
 
@@ -67,3 +72,54 @@ func TestCopyAny(t *testing.T) {
 	err = copyAny("hans sausage", s)
 	noErrWhenErrExpected(t, err)
 }
+
+func TestCopyAnyConversions(t *testing.T) {
+	type celsius float64
+	type name string
+	var stringer fmt.Stringer
+	tests := []struct {
+		v    any
+		to   any
+		fail bool
+		exp  any
+	}{
+		{v: 300, to: new(uint8), fail: true},
+		{v: 255, to: new(uint8), exp: uint8(255)},
+		{v: -1, to: new(uint), fail: true},
+		{v: uint64(math.MaxUint64), to: new(int64), fail: true},
+		{v: 1 << 53, to: new(float64), exp: float64(1 << 53)},
+		{v: 1<<53 + 1, to: new(float64), fail: true},
+		{v: 1<<24 + 1, to: new(float32), fail: true},
+		{v: uint32(1<<24 + 1), to: new(float32), fail: true},
+		{v: 1 << 24, to: new(float32), exp: float32(1 << 24)},
+		{v: 3.9, to: new(int), fail: true},
+		{v: math.NaN(), to: new(int), fail: true},
+		{v: 1e20, to: new(int64), fail: true},
+		{v: -2.0, to: new(uint), fail: true},
+		{v: 1e300, to: new(float32), fail: true},
+		{v: 21.5, to: new(celsius), exp: celsius(21.5)},
+		{v: 65, to: new(string), fail: true},
+		{v: "abc", to: new(name), exp: name("abc")},
+		{v: "abc", to: new([]byte), exp: []byte("abc")},
+		{v: []byte("abc"), to: new(string), exp: "abc"},
+		{v: "true", to: new(bool), fail: true},
+		{v: []int{1, 2}, to: new([]float64), exp: []float64{1, 2}},
+		{v: []float64{1, 2.5}, to: new([]int), fail: true},
+		{v: 42, to: new(*int), exp: ptrVal(42)},
+		{v: 42, to: new(*uint8), exp: ptrVal(uint8(42))},
+		{v: 42, to: new(any), exp: any(42)},
+		{v: 42, to: &stringer, fail: true},
+		{v: nil, to: new(*int), exp: (*int)(nil)},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			err := copyAny(test.v, test.to)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.exp, reflect.ValueOf(test.to).Elem().Interface())
+		})
+	}
+}
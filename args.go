@@ -0,0 +1,69 @@
+package scan
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseCall parses s in the form name or name(arg, ...). Args are either quoted (Go syntax) or bare strings.
+func parseCall(s string) (name string, args []string, err error) {
+	s = strings.TrimSpace(s)
+	open := strings.Index(s, "(")
+	if open < 0 {
+		return s, nil, nil
+	}
+	if !strings.HasSuffix(s, ")") {
+		return "", nil, errors.Errorf("missing closing ) in %q", s)
+	}
+	name = strings.TrimSpace(s[:open])
+	args, err = parseArgs(s[open+1 : len(s)-1])
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "parse args of %q", name)
+	}
+	return name, args, nil
+}
+
+func parseArgs(s string) ([]string, error) {
+	var args []string
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return nil, nil
+	}
+	for {
+		var arg string
+		if rest != "" && (rest[0] == '"' || rest[0] == '`') {
+			qs, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid quoted arg in %q", rest)
+			}
+			arg, _ = strconv.Unquote(qs)
+			rest = strings.TrimSpace(rest[len(qs):])
+		} else {
+			idx := strings.Index(rest, ",")
+			if idx < 0 {
+				idx = len(rest)
+			}
+			arg = strings.TrimSpace(rest[:idx])
+			rest = rest[idx:]
+		}
+		args = append(args, arg)
+		if rest == "" {
+			return args, nil
+		}
+		if rest[0] != ',' {
+			return nil, errors.Errorf("expect , but got %q", rest)
+		}
+		rest = strings.TrimSpace(rest[1:])
+	}
+}
+
+// formatCall is the inverse of parseCall
+func formatCall(name string, args []string) string {
+	qs := make([]string, len(args))
+	for i, arg := range args {
+		qs[i] = strconv.Quote(arg)
+	}
+	return name + "(" + strings.Join(qs, ", ") + ")"
+}
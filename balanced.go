@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Balanced wraps fnc, so that its capture respects balanced brackets and quotes: the next item is only matched
+// outside of (), [], {} and quoted strings. E.g. with a balanced func p, [{{p: p}}] captures [1,[2]] from [[1,[2]]].
+// Add it with Funcs.AddFunc.
+func Balanced(fnc Func) Func {
+	return balancedFunc{fnc}
+}
+
+type balancedFunc struct {
+	Func
+}
+
+var closing = map[byte]byte{
+	'(': ')',
+	'[': ']',
+	'{': '}',
+}
+
+// findBalancedSeparator is like findSeparator, but skips occurrences inside of brackets and quoted strings
+func findBalancedSeparator(s string, pos int, sep Item) (int, int, error) {
+	var stack []byte
+	var quote byte
+	for i := pos; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		if len(stack) == 0 {
+			switch sep := sep.(type) {
+			case string:
+				if sep != "" && len(s)-i >= len(sep) && s[i:i+len(sep)] == sep {
+					return i, len(sep), nil
+				}
+			case Whitespace:
+				if isWhite(rune(c)) {
+					return i, whiteLen(s[i:]), nil
+				}
+			default:
+				return -1, 0, errors.Errorf("next is not a string")
+			}
+		}
+		switch c {
+		case '"', '\'':
+			quote = c
+		case '(', '[', '{':
+			stack = append(stack, closing[c])
+		case ')', ']', '}':
+			if len(stack) > 0 {
+				if stack[len(stack)-1] != c {
+					return -1, 0, errors.Errorf("unbalanced %q at %d", c, i)
+				}
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	switch sep := sep.(type) {
+	case string:
+		return -1, 0, endOfInput{errors.Errorf("no balanced match for next %q", sep)}
+	default:
+		return -1, 0, endOfInput{errors.Errorf("no balanced match for next whitespace")}
+	}
+}
@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"testing"
+)
+
+func TestBalanced(t *testing.T) {
+	funcs := BuiltinFuncs()
+	funcs.AddFunc("call", Balanced(EvalFunc(func(s string) (any, error) {
+		return "<" + s + ">", nil
+	})))
+	tests := []struct {
+		template string
+		in       string
+		fail     bool
+		params   []ResultItem
+	}{
+		{
+			template: "[{{a: balanced}},{{b: balanced}}]",
+			in:       "[[1,2],[3,[4]]]",
+			params:   []ResultItem{{"a", "[1,2]"}, {"b", "[3,[4]]"}},
+		},
+		{
+			template: "{{a: call}}, {{b: int}}",
+			in:       "f(a, g(b, c)), 3",
+			params:   []ResultItem{{"a", "<f(a, g(b, c))>"}, {"b", 3}},
+		},
+		{
+			template: "{{a: balanced}} {{b: string}}",
+			in:       `{"x": "a b", "y": [1, 2]} rest`,
+			params:   []ResultItem{{"a", `{"x": "a b", "y": [1, 2]}`}, {"b", "rest"}},
+		},
+		{
+			template: "{{a: balanced}}) {{b: string}}",
+			in:       `g('), (', x)) rest`,
+			params:   []ResultItem{{"a", `g('), (', x)`}, {"b", "rest"}},
+		},
+		{
+			template: "{{a: balanced | []int}}]",
+			in:       "1,2,3]",
+			params:   []ResultItem{{"a", []int{1, 2, 3}}},
+		},
+		{
+			template: "{{a: balanced}}, {{b: string}}",
+			in:       "f(a, b",
+			fail:     true,
+		},
+		{
+			template: "{{a: balanced}}, {{b: string}}",
+			in:       "f(a], b",
+			fail:     true,
+		},
+		{
+			template: "{{a: string}}, {{b: string}}",
+			in:       "f(a, b)",
+			params:   []ResultItem{{"a", "f(a"}, {"b", "b)"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template+"/"+test.in, func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			res, err := tpl.Eval(test.in, funcs)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
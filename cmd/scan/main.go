@@ -0,0 +1,253 @@
+// Command scan evaluates each line of its input with a template and writes the results as NDJSON, JSON, CSV or TSV.
+//
+// Usage:
+//
+//	scan [flags] [file ...]
+//
+// If no files are given, stdin is read. Columns are named after the evalers of the template(s).
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mazzegi/scan"
+	"github.com/pkg/errors"
+)
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+const (
+	onErrorFail = "fail"
+	onErrorSkip = "skip"
+	onErrorWarn = "warn"
+)
+
+type options struct {
+	template     string
+	templateFile string
+	patternsFile string
+	format       string
+	onError      string
+	skip         int
+	skipPrefix   string
+	noHeader     bool
+	maxLine      int
+	trace        bool
+	color        bool
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var opts options
+	flags := flag.NewFlagSet("scan", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&opts.template, "t", "", "template pattern")
+	flags.StringVar(&opts.templateFile, "tf", "", "file containing the template pattern")
+	flags.StringVar(&opts.patternsFile, "patterns", "", "file containing several patterns (one per line), which are tried in order")
+	flags.StringVar(&opts.format, "o", formatNDJSON, "output format: ndjson, json, csv or tsv")
+	flags.StringVar(&opts.onError, "on-error", onErrorFail, "error policy for lines which don't match: fail, skip or warn")
+	flags.IntVar(&opts.skip, "skip", 0, "number of lines to skip at the beginning of each input")
+	flags.StringVar(&opts.skipPrefix, "skip-prefix", "", "skip lines starting with this prefix (e.g. #)")
+	flags.BoolVar(&opts.noHeader, "no-header", false, "don't write a header row for csv and tsv")
+	flags.IntVar(&opts.maxLine, "max-line", bufio.MaxScanTokenSize, "maximum line length in bytes")
+	flags.BoolVar(&opts.trace, "trace", false, "instead of writing results, print how each line is matched by the template(s)")
+	flags.BoolVar(&opts.color, "color", false, "colourise trace output")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	switch opts.onError {
+	case onErrorFail, onErrorSkip, onErrorWarn:
+	default:
+		return errors.Errorf("invalid error policy %q", opts.onError)
+	}
+
+	tpls, err := loadTemplates(opts)
+	if err != nil {
+		return err
+	}
+	out := stdout
+	if opts.trace {
+		out = io.Discard
+	}
+	w, err := newWriter(opts.format, out, columns(tpls), !opts.noHeader)
+	if err != nil {
+		return err
+	}
+	funcs := scan.BuiltinFuncs()
+
+	process := func(name string, r io.Reader) error {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 4096), opts.maxLine)
+		var lineNo int
+		for scanner.Scan() {
+			lineNo++
+			if lineNo <= opts.skip {
+				continue
+			}
+			ln := strings.TrimSpace(scanner.Text())
+			if ln == "" || (opts.skipPrefix != "" && strings.HasPrefix(ln, opts.skipPrefix)) {
+				continue
+			}
+			if opts.trace {
+				fmt.Fprintf(stdout, "%s:%d\n", name, lineNo)
+				if err := traceAll(tpls, ln, funcs, stdout, opts.color); err != nil {
+					return err
+				}
+				continue
+			}
+			res, err := evalFirst(tpls, ln, funcs)
+			if err != nil {
+				switch opts.onError {
+				case onErrorSkip:
+					continue
+				case onErrorWarn:
+					fmt.Fprintf(stderr, "%s:%d: %v\n", name, lineNo, err)
+					continue
+				default:
+					return errors.Wrapf(err, "%s:%d", name, lineNo)
+				}
+			}
+			err = w.Write(res)
+			if err != nil {
+				return errors.Wrap(err, "write")
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return errors.Wrapf(err, "%s:%d", name, lineNo+1)
+		}
+		return nil
+	}
+
+	if flags.NArg() == 0 {
+		err = process("stdin", stdin)
+	} else {
+		for _, file := range flags.Args() {
+			err = processFile(file, process)
+			if err != nil {
+				break
+			}
+		}
+	}
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func processFile(file string, process func(name string, r io.Reader) error) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return process(file, f)
+}
+
+func loadTemplates(opts options) ([]*scan.Template, error) {
+	var given int
+	for _, f := range []string{opts.template, opts.templateFile, opts.patternsFile} {
+		if f != "" {
+			given++
+		}
+	}
+	if given > 1 {
+		return nil, errors.Errorf("more than one template given. use only one of -t, -tf or -patterns")
+	}
+
+	var patterns []string
+	switch {
+	case opts.template != "":
+		patterns = append(patterns, opts.template)
+	case opts.templateFile != "":
+		bs, err := os.ReadFile(opts.templateFile)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, strings.TrimSpace(string(bs)))
+	case opts.patternsFile != "":
+		bs, err := os.ReadFile(opts.patternsFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, ln := range strings.Split(string(bs), "\n") {
+			ln = strings.TrimSpace(ln)
+			if ln == "" || strings.HasPrefix(ln, "#") {
+				continue
+			}
+			patterns = append(patterns, ln)
+		}
+	default:
+		return nil, errors.Errorf("no template given. use one of -t, -tf or -patterns")
+	}
+	if len(patterns) == 0 {
+		return nil, errors.Errorf("no patterns found")
+	}
+
+	var tpls []*scan.Template
+	for i, pattern := range patterns {
+		tpl, err := scan.ParseTemplate(fmt.Sprintf("pattern-%d", i+1), pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "parse pattern %q", pattern)
+		}
+		tpls = append(tpls, tpl)
+	}
+	return tpls, nil
+}
+
+// columns returns the evaler names of all templates in order of their first appearance
+func columns(tpls []*scan.Template) []string {
+	var cols []string
+	seen := map[string]bool{}
+	for _, tpl := range tpls {
+		for _, ev := range tpl.Evalers() {
+			if seen[ev.Name()] {
+				continue
+			}
+			seen[ev.Name()] = true
+			cols = append(cols, ev.Name())
+		}
+	}
+	return cols
+}
+
+func evalFirst(tpls []*scan.Template, ln string, funcs scan.Funcs) (*scan.Result, error) {
+	var lastErr error
+	for _, tpl := range tpls {
+		res, err := tpl.Eval(ln, funcs)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = errors.Wrapf(err, "%s", tpl.Name())
+	}
+	if len(tpls) > 1 {
+		return nil, errors.Wrapf(lastErr, "no pattern matches")
+	}
+	return nil, lastErr
+}
+
+// traceAll renders the traces of all templates up to the first one which matches
+func traceAll(tpls []*scan.Template, ln string, funcs scan.Funcs, w io.Writer, color bool) error {
+	for _, tpl := range tpls {
+		if len(tpls) > 1 {
+			fmt.Fprintf(w, "%s:\n", tpl.Name())
+		}
+		_, tr, err := tpl.EvalTrace(ln, funcs)
+		if rerr := tr.Render(w, color); rerr != nil {
+			return rerr
+		}
+		if err == nil {
+			return nil
+		}
+	}
+	return nil
+}
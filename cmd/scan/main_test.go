@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const input = `# points
+p1: 1, 2
+p2: 3.5, -4
+oops
+p3 at 7|8
+`
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	patternsFile := filepath.Join(dir, "patterns")
+	err := os.WriteFile(patternsFile, []byte("# tried in order\n{{name: string}}: {{x: float}}, {{y: float}}\n{{name: string}} at {{x: int}}|{{y: int}}\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		args   []string
+		fail   bool
+		expect string
+	}{
+		{
+			args: []string{"-t", "{{name: string}}: {{x: float}}, {{y: float}}"},
+			fail: true,
+		},
+		{
+			args:   []string{"-t", "{{name: string}}: {{x: float}}, {{y: float}}", "-skip", "1", "-on-error", "skip"},
+			expect: "{\"name\":\"p1\",\"x\":1,\"y\":2}\n{\"name\":\"p2\",\"x\":3.5,\"y\":-4}\n",
+		},
+		{
+			args:   []string{"-t", "{{name: string}}: {{x: float}}, {{y: float}}", "-skip-prefix", "#", "-on-error", "warn", "-o", "json"},
+			expect: "[\n{\"name\":\"p1\",\"x\":1,\"y\":2},\n{\"name\":\"p2\",\"x\":3.5,\"y\":-4}\n]\n",
+		},
+		{
+			args:   []string{"-patterns", patternsFile, "-skip", "1", "-on-error", "skip", "-o", "csv"},
+			expect: "name,x,y\np1,1,2\np2,3.5,-4\np3,7,8\n",
+		},
+		{
+			args:   []string{"-t", "{{name: string}}: {{nums: []int}}", "-o", "tsv", "-on-error", "skip", "-no-header"},
+			expect: "p1\t1,2\n",
+		},
+		{
+			args: []string{"-t", "{{name: string}}: {{x: float}}", "-o", "xml"},
+			fail: true,
+		},
+		{
+			args: []string{"-o", "csv"},
+			fail: true,
+		},
+		{
+			args: []string{"-t", "{{name: string}}: {{x: float}}, {{y: float}}", "-patterns", patternsFile},
+			fail: true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			stdout := &bytes.Buffer{}
+			stderr := &bytes.Buffer{}
+			err := run(test.args, bytes.NewBufferString(input), stdout, stderr)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail")
+			}
+			if test.expect != stdout.String() {
+				t.Fatalf("want %q, have %q", test.expect, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunTrace(t *testing.T) {
+	stdout := &bytes.Buffer{}
+	err := run([]string{"-t", "{{name: string}}: {{x: int}}", "-skip", "1", "-o", "csv", "-trace"}, bytes.NewBufferString(input), stdout, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("expect NOT to fail, but got %v", err)
+	}
+	for _, expect := range []string{
+		"stdin:2\np1: 1, 2\n^~ name: string(\"p1\") split at 2 => \"p1\"\n  ^ literal \":\"\n",
+		"stdin:4\noops\n^ name: string(\"\") failed: no match for next \":\"\n",
+	} {
+		if !strings.Contains(stdout.String(), expect) {
+			t.Fatalf("expect %q in %q", expect, stdout.String())
+		}
+	}
+	if strings.Contains(stdout.String(), "name,x") {
+		t.Fatalf("expect no csv output in trace mode")
+	}
+}
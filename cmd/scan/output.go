@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/mazzegi/scan"
+	"github.com/pkg/errors"
+)
+
+const (
+	formatNDJSON = "ndjson"
+	formatJSON   = "json"
+	formatCSV    = "csv"
+	formatTSV    = "tsv"
+)
+
+type writer interface {
+	Write(res *scan.Result) error
+	Close() error
+}
+
+func newWriter(format string, w io.Writer, cols []string, header bool) (writer, error) {
+	switch format {
+	case formatNDJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), cols: cols}, nil
+	case formatJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), cols: cols, array: true}, nil
+	case formatCSV:
+		return newCSVWriter(w, ',', cols, header), nil
+	case formatTSV:
+		return newCSVWriter(w, '\t', cols, header), nil
+	default:
+		return nil, errors.Errorf("invalid output format %q", format)
+	}
+}
+
+// jsonWriter writes results as objects with keys in column order
+type jsonWriter struct {
+	w     *bufio.Writer
+	cols  []string
+	array bool
+	count int
+}
+
+func (jw *jsonWriter) Write(res *scan.Result) error {
+	values := map[string]any{}
+	for _, item := range res.Items {
+		values[item.Name] = item.Value
+	}
+	var sb strings.Builder
+	sb.WriteString("{")
+	var n int
+	for _, col := range jw.cols {
+		v, ok := values[col]
+		if !ok {
+			continue
+		}
+		key, _ := json.Marshal(col)
+		val, err := json.Marshal(v)
+		if err != nil {
+			return errors.Wrapf(err, "marshal %q", col)
+		}
+		if n > 0 {
+			sb.WriteString(",")
+		}
+		sb.Write(key)
+		sb.WriteString(":")
+		sb.Write(val)
+		n++
+	}
+	sb.WriteString("}")
+
+	if jw.array {
+		if jw.count == 0 {
+			jw.w.WriteString("[\n")
+		} else {
+			jw.w.WriteString(",\n")
+		}
+	}
+	jw.w.WriteString(sb.String())
+	if !jw.array {
+		jw.w.WriteString("\n")
+	}
+	jw.count++
+	return nil
+}
+
+func (jw *jsonWriter) Close() error {
+	if jw.array {
+		if jw.count == 0 {
+			jw.w.WriteString("[")
+		}
+		jw.w.WriteString("\n]\n")
+	}
+	return jw.w.Flush()
+}
+
+type csvWriter struct {
+	w      *csv.Writer
+	cols   []string
+	header bool
+}
+
+func newCSVWriter(w io.Writer, comma rune, cols []string, header bool) *csvWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &csvWriter{
+		w:      cw,
+		cols:   cols,
+		header: header,
+	}
+}
+
+func (cw *csvWriter) writeHeader() error {
+	if !cw.header {
+		return nil
+	}
+	cw.header = false
+	return cw.w.Write(cw.cols)
+}
+
+func (cw *csvWriter) Write(res *scan.Result) error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	values := map[string]any{}
+	for _, item := range res.Items {
+		values[item.Name] = item.Value
+	}
+	rec := make([]string, len(cw.cols))
+	for i, col := range cw.cols {
+		if v, ok := values[col]; ok {
+			rec[i] = formatValue(v)
+		}
+	}
+	return cw.w.Write(rec)
+}
+
+func (cw *csvWriter) Close() error {
+	if err := cw.writeHeader(); err != nil {
+		return err
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatValue formats v for a csv cell. Slices are joined by comma.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		ss := make([]string, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			ss[i] = formatValue(rv.Index(i).Interface())
+		}
+		return strings.Join(ss, ",")
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,41 @@
+// Command scangen generates a Go struct and a reflection-free parse function from a scan template.
+//
+// Usage with go generate:
+//
+//	//go:generate go run github.com/mazzegi/scan/cmd/scangen -type Command -pattern "{{action: string}} x={{x0: int}}..{{x1: int}}" -o command_gen.go
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/mazzegi/scan/gen"
+)
+
+func main() {
+	var cfg gen.Config
+	var out string
+	flag.StringVar(&cfg.TypeName, "type", "", "name of the generated struct type")
+	flag.StringVar(&cfg.Pattern, "pattern", "", "template pattern")
+	flag.StringVar(&cfg.Package, "package", os.Getenv("GOPACKAGE"), "package name of the generated file. defaults to $GOPACKAGE")
+	flag.StringVar(&out, "o", "", "output file. defaults to stdout")
+	flag.Parse()
+
+	buf := &bytes.Buffer{}
+	err := gen.Generate(buf, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scangen: %v\n", err)
+		os.Exit(1)
+	}
+	if out == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	err = os.WriteFile(out, buf.Bytes(), 0644)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scangen: %v\n", err)
+		os.Exit(1)
+	}
+}
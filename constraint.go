@@ -0,0 +1,165 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Constraint restricts the values accepted by an evaler. Constraints follow the func name, separated by |, e.g.
+//
+//	{{port: int|min=1|max=65535}}
+//	{{name: string|re=^[a-z]+$}}
+//	{{level: string|in=debug,info,warn}}
+//
+// min and max compare numbers by value and strings, slices and maps by length. re matches the formatted value
+// against a regular expression and in requires the formatted value to be one of a comma-separated list.
+// Args containing a | are quoted in Go syntax, e.g. re="^(a|b)$".
+type Constraint struct {
+	Kind string
+	Arg  string
+	num  float64
+	set  []string
+	re   *regexp.Regexp
+}
+
+// ConstraintError is returned, when a value violates a constraint
+type ConstraintError struct {
+	Evaler     string
+	Constraint Constraint
+	Value      any
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("evaler %q: value %v violates constraint %s", e.Evaler, e.Value, e.Constraint)
+}
+
+// isConstraint reports whether s is in the form kind=arg rather than a pipeline stage like replace("a", "b")
+func isConstraint(s string) bool {
+	eq := strings.Index(s, "=")
+	open := strings.IndexAny(s, "(\"`")
+	return eq >= 0 && (open < 0 || eq < open)
+}
+
+func parseConstraint(s string) (Constraint, error) {
+	kind, arg, ok := strings.Cut(s, "=")
+	if !ok {
+		return Constraint{}, errors.Errorf("invalid constraint %q. not in form <kind=arg>", s)
+	}
+	kind = strings.TrimSpace(kind)
+	arg = strings.TrimSpace(arg)
+	if arg != "" && (arg[0] == '"' || arg[0] == '`') {
+		uq, err := strconv.Unquote(arg)
+		if err != nil {
+			return Constraint{}, errors.Wrapf(err, "unquote arg of constraint %q", kind)
+		}
+		arg = uq
+	}
+	c := Constraint{
+		Kind: kind,
+		Arg:  arg,
+	}
+	switch kind {
+	case "min", "max":
+		f, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return Constraint{}, errors.Wrapf(err, "constraint %q: parse number", kind)
+		}
+		c.num = f
+	case "re":
+		re, err := regexp.Compile(arg)
+		if err != nil {
+			return Constraint{}, errors.Wrapf(err, "constraint %q: compile", kind)
+		}
+		c.re = re
+	case "in":
+		for _, e := range strings.Split(arg, ",") {
+			c.set = append(c.set, strings.TrimSpace(e))
+		}
+	default:
+		return Constraint{}, errors.Errorf("unknown constraint %q", kind)
+	}
+	return c, nil
+}
+
+func (c Constraint) String() string {
+	arg := c.Arg
+	if arg == "" || strings.ContainsAny(arg, "|\"`{}<>\\ \t") {
+		arg = strconv.Quote(arg)
+	}
+	return c.Kind + "=" + arg
+}
+
+// check returns false, if v violates the constraint
+func (c Constraint) check(v any) (bool, error) {
+	switch c.Kind {
+	case "min", "max":
+		n, ok := measure(v)
+		if !ok {
+			return false, errors.Errorf("constraint %q is not applicable to %T", c.Kind, v)
+		}
+		if c.Kind == "min" {
+			return n >= c.num, nil
+		}
+		return n <= c.num, nil
+	case "re":
+		return c.re.MatchString(fmt.Sprint(v)), nil
+	case "in":
+		s := fmt.Sprint(v)
+		for _, e := range c.set {
+			if e == s {
+				return true, nil
+			}
+		}
+		return false, nil
+	default:
+		return false, errors.Errorf("unknown constraint %q", c.Kind)
+	}
+}
+
+// measure returns the value of numbers and the length of strings, slices and maps
+func measure(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(rv.Len()), true
+	default:
+		return 0, false
+	}
+}
+
+// splitUnquoted splits s at sep, but not inside of quoted strings
+func splitUnquoted(s string, sep rune) []string {
+	var parts []string
+	var quote rune
+	var escaped bool
+	start := 0
+	for i, r := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '`':
+			quote = r
+		case r == sep:
+			parts = append(parts, s[start:i])
+			start = i + len(string(sep))
+		}
+	}
+	return append(parts, s[start:])
+}
@@ -0,0 +1,112 @@
+package scan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestConstraints(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template  string
+		in        string
+		parseFail bool
+		violated  string
+		evalFail  bool
+		params    []ResultItem
+	}{
+		{
+			template: "port={{port: int|min=1|max=65535}}",
+			in:       "port=8080",
+			params:   []ResultItem{{"port", 8080}},
+		},
+		{
+			template: "port={{port: int|min=1|max=65535}}",
+			in:       "port=0",
+			violated: "min=1",
+		},
+		{
+			template: "port={{port: int|min=1|max=65535}}",
+			in:       "port=65536",
+			violated: "max=65535",
+		},
+		{
+			template: "name={{name: string|re=^[a-z]+$}}",
+			in:       "name=bob",
+			params:   []ResultItem{{"name", "bob"}},
+		},
+		{
+			template: "name={{name: string|re=^[a-z]+$}}",
+			in:       "name=Bob",
+			violated: "re=^[a-z]+$",
+		},
+		{
+			template: `name={{name: string|re="^(alice|bob)$"}}`,
+			in:       "name=alice",
+			params:   []ResultItem{{"name", "alice"}},
+		},
+		{
+			template: "{{level: string|in=debug, info, warn}}: {{msg: string|min=1}}",
+			in:       "info: started",
+			params:   []ResultItem{{"level", "info"}, {"msg", "started"}},
+		},
+		{
+			template: "{{level: string|in=debug, info, warn}}: {{msg: string|min=1}}",
+			in:       "error: started",
+			violated: "in=\"debug, info, warn\"",
+		},
+		{
+			template: "{{tags: []string|max=2}}",
+			in:       "a, b, c",
+			violated: "max=2",
+		},
+		{
+			template: "{{on: bool|min=1}}",
+			in:       "true",
+			evalFail: true,
+		},
+		{
+			template:  "{{port: int|min=a}}",
+			parseFail: true,
+		},
+		{
+			template:  "{{port: int|between=1,2}}",
+			parseFail: true,
+		},
+		{
+			template:  "{{name: string|re=[a-z}}",
+			parseFail: true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			if test.parseFail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			tpl2, err := ParseTemplate("test", tpl.String())
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.String(), tpl2.String())
+
+			res, err := tpl.Eval(test.in, funcs)
+			if test.evalFail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			if test.violated != "" {
+				var cerr *ConstraintError
+				if !errors.As(err, &cerr) {
+					t.Fatalf("expect constraint error, got %v", err)
+				}
+				assertEqual(t, test.violated, cerr.Constraint.String())
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
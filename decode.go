@@ -0,0 +1,314 @@
+package scan
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// pathSegment is a part of a dotted evaler name. If appendSlice is set, the value is appended to a slice.
+type pathSegment struct {
+	name        string
+	appendSlice bool
+}
+
+// parsePath splits an evaler name like "point.x" or "tags[]" into segments. The name "." denotes the target itself.
+func parsePath(name string) ([]pathSegment, error) {
+	if name == "." {
+		return nil, nil
+	}
+	var path []pathSegment
+	parts := strings.Split(name, ".")
+	for i, part := range parts {
+		seg := pathSegment{name: part}
+		if strings.HasSuffix(part, "[]") {
+			if i < len(parts)-1 {
+				return nil, errors.Errorf("[] is only supported in the last part of %q", name)
+			}
+			seg.name = strings.TrimSuffix(part, "[]")
+			seg.appendSlice = true
+		}
+		if seg.name == "" {
+			return nil, errors.Errorf("empty part in %q", name)
+		}
+		path = append(path, seg)
+	}
+	return path, nil
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// decodePath sets the value at path in rv. Struct fields are matched like encoding/json does, fields that don't exist are ignored.
+func decodePath(rv reflect.Value, path []pathSegment, v any) error {
+	if len(path) == 0 && (v == nil || reflect.TypeOf(v).AssignableTo(rv.Type())) {
+		// nil leaves pointers nil
+		return assignValue(rv, v)
+	}
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	if len(path) == 0 {
+		return assignValue(rv, v)
+	}
+	if rv.Kind() == reflect.Interface {
+		m, ok := rv.Interface().(map[string]any)
+		if !ok {
+			m = map[string]any{}
+			rv.Set(reflect.ValueOf(m))
+		}
+		rv = reflect.ValueOf(m)
+	}
+
+	seg := path[0]
+	switch rv.Kind() {
+	case reflect.Struct:
+		f, ok := fieldByName(rv, seg.name)
+		if !ok {
+			return nil
+		}
+		return decodeSegment(f, seg, path[1:], v)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return errors.Errorf("cannot set %q in %s. key is not a string", seg.name, rv.Type())
+		}
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		key := reflect.ValueOf(seg.name).Convert(rv.Type().Key())
+		elem := reflect.New(rv.Type().Elem()).Elem()
+		if old := rv.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+		if err := decodeSegment(elem, seg, path[1:], v); err != nil {
+			return err
+		}
+		rv.SetMapIndex(key, elem)
+		return nil
+	default:
+		return errors.Errorf("cannot set %q in %s", seg.name, rv.Type())
+	}
+}
+
+func decodeSegment(rv reflect.Value, seg pathSegment, rest []pathSegment, v any) error {
+	if !seg.appendSlice {
+		return decodePath(rv, rest, v)
+	}
+	if rv.Kind() == reflect.Interface {
+		s, _ := rv.Interface().([]any)
+		elem := reflect.New(anyType).Elem()
+		if err := decodePath(elem, rest, v); err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(append(s, elem.Interface())))
+		return nil
+	}
+	if rv.Kind() != reflect.Slice {
+		return errors.Errorf("cannot append to %s", rv.Type())
+	}
+	elem := reflect.New(rv.Type().Elem()).Elem()
+	if err := decodePath(elem, rest, v); err != nil {
+		return err
+	}
+	rv.Set(reflect.Append(rv, elem))
+	return nil
+}
+
+// fieldByName finds the field of the struct rv with the name like encoding/json does: the json name or the field name
+// matches exactly or, if no field does, case-insensitively. Fields of embedded structs are searched as well.
+func fieldByName(rv reflect.Value, name string) (reflect.Value, bool) {
+	if f, ok := findField(rv, func(key string) bool { return key == name }); ok {
+		return f, true
+	}
+	return findField(rv, func(key string) bool { return strings.EqualFold(key, name) })
+}
+
+func findField(rv reflect.Value, match func(key string) bool) (reflect.Value, bool) {
+	typ := rv.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		switch key {
+		case "-":
+			continue
+		case "":
+			key = sf.Name
+		}
+		if match(key) {
+			return rv.Field(i), true
+		}
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.Anonymous || sf.Type.Kind() != reflect.Struct || !sf.IsExported() {
+			continue
+		}
+		if f, ok := findField(rv.Field(i), match); ok {
+			return f, true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// assignValue sets rv to v like copyAny does. A json.RawMessage (from the json func) is unmarshalled into rv, unless rv
+// holds raw bytes itself. Targets implementing json.Unmarshaler or encoding.TextUnmarshaler (for strings) unmarshal
+// values of other types. If there is no conversion, a map[string]string (e.g. from logfmt) is decoded key by key and
+// other values fill structs, maps and slices through JSON.
+func assignValue(rv reflect.Value, v any) error {
+	if raw, ok := v.(json.RawMessage); ok && !isByteSlice(rv.Type()) {
+		return json.Unmarshal(raw, rv.Addr().Interface())
+	}
+	if v != nil && !reflect.TypeOf(v).AssignableTo(rv.Type()) {
+		if rv.Kind() == reflect.Pointer {
+			elem := reflect.New(rv.Type().Elem())
+			if err := assignValue(elem.Elem(), v); err != nil {
+				return err
+			}
+			rv.Set(elem)
+			return nil
+		}
+		switch u := rv.Addr().Interface().(type) {
+		case json.Unmarshaler:
+			bs, err := json.Marshal(v)
+			if err != nil {
+				return err
+			}
+			return u.UnmarshalJSON(bs)
+		case encoding.TextUnmarshaler:
+			if s, ok := v.(string); ok {
+				return u.UnmarshalText([]byte(s))
+			}
+		}
+	}
+	err := convertValue(v, rv)
+	if err == nil || !errors.Is(err, errUnsupported) {
+		return err
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return err
+	}
+	if m, ok := v.(map[string]string); ok {
+		return decodeStringMap(rv, m)
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, rv.Addr().Interface())
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// decodeStringMap sets the fields or elements of rv, which match the keys of m, to the parsed values
+func decodeStringMap(rv reflect.Value, m map[string]string) error {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		rv = rv.Elem()
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var err error
+		switch rv.Kind() {
+		case reflect.Struct:
+			f, ok := fieldByName(rv, k)
+			if !ok {
+				continue
+			}
+			err = parseInto(f, m[k])
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return errors.Errorf("cannot set %q in %s. key is not a string", k, rv.Type())
+			}
+			if rv.IsNil() {
+				rv.Set(reflect.MakeMap(rv.Type()))
+			}
+			elem := reflect.New(rv.Type().Elem()).Elem()
+			err = parseInto(elem, m[k])
+			rv.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), elem)
+		default:
+			return errors.Errorf("cannot decode keys into %s", rv.Type())
+		}
+		if err != nil {
+			return errors.Wrapf(err, "key %q", k)
+		}
+	}
+	return nil
+}
+
+// parseInto parses s according to the type of rv
+func parseInto(rv reflect.Value, s string) error {
+	if rv.Kind() == reflect.Pointer {
+		elem := reflect.New(rv.Type().Elem())
+		if err := parseInto(elem.Elem(), s); err != nil {
+			return err
+		}
+		rv.Set(elem)
+		return nil
+	}
+	if rv.Addr().Type().Implements(textUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+	switch {
+	case rv.Kind() == reflect.String:
+		rv.SetString(s)
+	case rv.Kind() == reflect.Interface && rv.NumMethod() == 0:
+		rv.Set(reflect.ValueOf(s))
+	case rv.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case isInt(rv.Kind()):
+		n, err := strconv.ParseInt(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(n)
+	case isUint(rv.Kind()):
+		n, err := strconv.ParseUint(s, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(n)
+	case rv.Kind() == reflect.Float32 || rv.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	default:
+		return errors.Errorf("cannot parse %q into %s", s, rv.Type())
+	}
+	return nil
+}
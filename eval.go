@@ -1,6 +1,8 @@
 package scan
 
 import (
+	"context"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -10,6 +12,17 @@ type Evaler struct {
 	raw      string
 	name     string
 	funcName string
+	// pipe holds the funcs, which are called with the value of the previous stage
+	pipe        []string
+	constraints []Constraint
+	// column binds the evaler to a record column by index or header name (see Records)
+	column string
+	// def is passed to the func instead of an empty string, if hasDef is set
+	def      string
+	hasDef   bool
+	nullable bool
+	// line bounds the capture to the end of the line (see EachMatch)
+	line bool
 }
 
 func ParseEvaler(s string) (Evaler, error) {
@@ -22,7 +35,57 @@ func ParseEvaler(s string) (Evaler, error) {
 		return Evaler{}, errors.Errorf("invalid syntax. not in form <name:funcName>")
 	}
 	name = strings.TrimSpace(name)
-	funcName = strings.TrimSpace(funcName)
+	parts := splitUnquoted(funcName, '|')
+	funcName = strings.TrimSpace(parts[0])
+	var column string
+	if fparts := splitUnquoted(funcName, '@'); len(fparts) > 1 {
+		column = strings.TrimSpace(fparts[len(fparts)-1])
+		funcName = strings.TrimSpace(strings.Join(fparts[:len(fparts)-1], "@"))
+		if column == "" {
+			return Evaler{}, errors.Errorf("empty column")
+		}
+	}
+	var def string
+	var hasDef, nullable bool
+	if fparts := splitUnquoted(funcName, '='); len(fparts) > 1 {
+		funcName = strings.TrimSpace(fparts[0])
+		def = strings.TrimSpace(strings.Join(fparts[1:], "="))
+		if def != "" && (def[0] == '"' || def[0] == '`') {
+			uq, err := strconv.Unquote(def)
+			if err != nil {
+				return Evaler{}, errors.Wrap(err, "unquote default")
+			}
+			def = uq
+		}
+		hasDef = true
+	}
+	if strings.HasSuffix(funcName, "?") {
+		if hasDef {
+			return Evaler{}, errors.Errorf("an evaler cannot be nullable and have a default")
+		}
+		funcName = strings.TrimSpace(strings.TrimSuffix(funcName, "?"))
+		nullable = true
+	}
+	var pipe []string
+	var constraints []Constraint
+	for _, part := range parts[1:] {
+		if !isConstraint(part) {
+			if len(constraints) > 0 {
+				return Evaler{}, errors.Errorf("pipeline stage %q must precede the constraints", strings.TrimSpace(part))
+			}
+			stage := strings.TrimSpace(part)
+			if _, _, err := parseCall(stage); err != nil || stage == "" {
+				return Evaler{}, errors.Errorf("invalid pipeline stage %q", stage)
+			}
+			pipe = append(pipe, stage)
+			continue
+		}
+		c, err := parseConstraint(part)
+		if err != nil {
+			return Evaler{}, err
+		}
+		constraints = append(constraints, c)
+	}
 	if name == "" {
 		return Evaler{}, errors.Errorf("empty name")
 	}
@@ -31,21 +94,158 @@ func ParseEvaler(s string) (Evaler, error) {
 	}
 
 	e := Evaler{
-		raw:      s,
-		name:     name,
-		funcName: funcName,
+		raw:         s,
+		name:        name,
+		funcName:    funcName,
+		pipe:        pipe,
+		constraints: constraints,
+		column:      column,
+		def:         def,
+		hasDef:      hasDef,
+		nullable:    nullable,
 	}
 	return e, nil
 }
 
+func (e Evaler) Name() string {
+	return e.name
+}
+
+// FuncName returns the name of the func, which is called with the captured text
+func (e Evaler) FuncName() string {
+	return e.funcName
+}
+
+// Pipeline returns the funcs, which are called in order with the value of the previous func
+func (e Evaler) Pipeline() []string {
+	return append([]string{}, e.pipe...)
+}
+
+// Default returns the value, which is evaluated instead of an empty capture
+func (e Evaler) Default() (string, bool) {
+	return e.def, e.hasDef
+}
+
+// Column returns the record column, the evaler is bound to, by index or header name
+func (e Evaler) Column() string {
+	return e.column
+}
+
+// Nullable reports whether an empty capture yields nil
+func (e Evaler) Nullable() bool {
+	return e.nullable
+}
+
+// optional reports whether the evaler accepts an empty capture
+func (e Evaler) optional() bool {
+	return e.hasDef || e.nullable
+}
+
+func (e Evaler) Constraints() []Constraint {
+	return append([]Constraint{}, e.constraints...)
+}
+
+func (e Evaler) String() string {
+	return defaultLeftDelim + e.spec() + defaultRightDelim
+}
+
+// spec returns the canonical evaler text without delimiters
+func (e Evaler) spec() string {
+	s := e.name + ": " + e.funcName
+	switch {
+	case e.nullable:
+		s += "?"
+	case e.hasDef:
+		def := e.def
+		if def == "" || strings.ContainsAny(def, "|=?@\"`{}<>\\ \t") {
+			def = strconv.Quote(def)
+		}
+		s += "=" + def
+	}
+	if e.column != "" {
+		s += "@" + e.column
+	}
+	for _, stage := range e.pipe {
+		s += " | " + stage
+	}
+	for _, c := range e.constraints {
+		s += "|" + c.String()
+	}
+	return s
+}
+
 func (e Evaler) Eval(s string, funcs Funcs) (any, error) {
-	fnc, ok := funcs[e.funcName]
-	if !ok {
-		return nil, errors.Errorf("no such func %q", e.funcName)
+	return e.EvalContext(context.Background(), s, funcs)
+}
+
+func (e Evaler) EvalContext(ctx context.Context, s string, funcs Funcs) (any, error) {
+	fnc, err := lookupFunc(funcs, e.funcName)
+	if err != nil {
+		return nil, err
+	}
+	if s == "" {
+		switch {
+		case e.nullable:
+			return nil, nil
+		case e.hasDef:
+			s = e.def
+		}
 	}
-	v, err := fnc(s)
+	v, err := fnc.EvalContext(ctx, s)
 	if err != nil {
 		return nil, errors.Wrapf(err, "call-func %q", e.funcName)
 	}
+	for _, stage := range e.pipe {
+		fnc, err := lookupFunc(funcs, stage)
+		if err != nil {
+			return nil, err
+		}
+		v, err = evalValue(ctx, fnc, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "call-func %q", stage)
+		}
+	}
+	for _, c := range e.constraints {
+		ok, err := c.check(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "evaler %q", e.name)
+		}
+		if !ok {
+			return nil, &ConstraintError{Evaler: e.name, Constraint: c, Value: v}
+		}
+	}
 	return v, nil
 }
+
+// capture returns the length of the token at the start of s, if the func of the evaler is a Capturer
+func (e Evaler) capture(s string, funcs Funcs) (int, bool) {
+	fnc, err := lookupFunc(funcs, e.funcName)
+	if err != nil {
+		return 0, false
+	}
+	c, ok := fnc.(Capturer)
+	if !ok {
+		return 0, false
+	}
+	return c.Capture(s)
+}
+
+// balanced reports whether the func of the evaler was wrapped by Balanced
+func (e Evaler) balanced(funcs Funcs) bool {
+	fnc, err := lookupFunc(funcs, e.funcName)
+	if err != nil {
+		return false
+	}
+	_, ok := fnc.(balancedFunc)
+	return ok
+}
+
+// checkFuncs returns an error, if a func of the evaler cannot be found in funcs
+func (e Evaler) checkFuncs(funcs Funcs) error {
+	for _, name := range append([]string{e.funcName}, e.pipe...) {
+		if _, err := lookupFunc(funcs, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
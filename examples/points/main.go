@@ -28,25 +28,7 @@ p2: -34.45534, 4e-3, 21
 
 func main() {
 
-	tplPoint, err := scan.ParseTemplate("point", "{{x:float}},{{y:float}},{{z:float}}")
-	if err != nil {
-		panic(err)
-	}
-	funcs := scan.BuiltinFuncs()
-	funcs.Add("point", func(s string) (any, error) {
-		var p Point
-		res, err := tplPoint.Eval(s, funcs)
-		if err != nil {
-			return nil, err
-		}
-		err = res.Scan(&p.X, &p.Y, &p.Z)
-		if err != nil {
-			return nil, err
-		}
-		return p, nil
-	})
-
-	ps, err := scan.Lines[NamedPoint]("{{name: string}}:{{point: point}}", funcs, bytes.NewBufferString(input))
+	ps, err := scan.Lines[NamedPoint]("{{name: string}}:{{point.x: float}},{{point.y: float}},{{point.z: float}}", scan.BuiltinFuncs(), bytes.NewBufferString(input))
 	if err != nil {
 		panic(err)
 	}
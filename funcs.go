@@ -1,57 +1,441 @@
 package scan
 
 import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/mazzegi/slices"
 	"github.com/pkg/errors"
 )
 
+// Func is implemented by everything which can be registered in Funcs
+type Func interface {
+	EvalContext(ctx context.Context, s string) (any, error)
+}
+
 type EvalFunc func(s string) (any, error)
 
+func (f EvalFunc) EvalContext(_ context.Context, s string) (any, error) {
+	return f(s)
+}
+
+// EvalContextFunc is the context-aware flavour of EvalFunc, e.g. for funcs which do lookups
+type EvalContextFunc func(ctx context.Context, s string) (any, error)
+
+func (f EvalContextFunc) EvalContext(ctx context.Context, s string) (any, error) {
+	return f(ctx, s)
+}
+
+// ValueFunc is implemented by funcs, which accept the typed value of a previous pipeline stage.
+// Other funcs receive the value formatted as string.
+type ValueFunc interface {
+	Func
+	EvalValue(ctx context.Context, v any) (any, error)
+}
+
+// EvalValueFunc is a ValueFunc. Called with a captured string, v is that string.
+type EvalValueFunc func(v any) (any, error)
+
+func (f EvalValueFunc) EvalContext(_ context.Context, s string) (any, error) {
+	return f(s)
+}
+
+func (f EvalValueFunc) EvalValue(_ context.Context, v any) (any, error) {
+	return f(v)
+}
+
+// Funcs maps func names to EvalFuncs. Other funcs, like context-aware funcs or Capturers, are added with AddFunc.
+// They are held in a companion map under an internal name, while their own name maps to an EvalFunc calling them
+// with a background context. Use Add to replace such a func by a plain EvalFunc.
 type Funcs map[string]EvalFunc
 
+// companionName is the name, under which the companion map of funcs, which are no plain EvalFuncs, is stored
+const companionName = "\x00funcs"
+
+// companion holds the funcs, which are no plain EvalFuncs, together with the identity of the owning Funcs,
+// so that copies of a Funcs don't modify the original
+type companion struct {
+	owner uintptr
+	funcs map[string]Func
+}
+
+// companion returns the companion map of fs. With create, a missing or copied companion map is (re-)created.
+func (fs Funcs) companion(create bool) map[string]Func {
+	var c companion
+	if fnc, ok := fs[companionName]; ok {
+		v, _ := fnc("")
+		c = v.(companion)
+	}
+	if !create {
+		return c.funcs
+	}
+	owner := reflect.ValueOf(fs).Pointer()
+	if c.funcs != nil && c.owner == owner {
+		return c.funcs
+	}
+	own := companion{owner: owner, funcs: map[string]Func{}}
+	for name, fnc := range c.funcs {
+		own.funcs[name] = fnc
+	}
+	fs[companionName] = func(string) (any, error) {
+		return own, nil
+	}
+	return own.funcs
+}
+
 func (fs Funcs) Add(name string, fnc EvalFunc) {
+	if _, ok := fs.companion(false)[name]; ok {
+		delete(fs.companion(true), name)
+	}
 	fs[name] = fnc
 }
 
+func (fs Funcs) AddContext(name string, fnc EvalContextFunc) {
+	fs.AddFunc(name, fnc)
+}
+
+func (fs Funcs) AddValue(name string, fnc EvalValueFunc) {
+	fs.AddFunc(name, fnc)
+}
+
+// AddFunc adds any Func, e.g. a CaptureFunc or a func wrapped by Balanced
+func (fs Funcs) AddFunc(name string, fnc Func) {
+	if ef, ok := fnc.(EvalFunc); ok {
+		fs.Add(name, ef)
+		return
+	}
+	fs.companion(true)[name] = fnc
+	fs[name] = func(s string) (any, error) {
+		return fnc.EvalContext(context.Background(), s)
+	}
+}
+
+// AddFuncs adds all funcs of other to fs
+func (fs Funcs) AddFuncs(other Funcs) {
+	for name := range other {
+		if fnc, ok := other.Lookup(name); ok {
+			fs.AddFunc(name, fnc)
+		}
+	}
+}
+
+// Lookup returns the func with name as it was added
+func (fs Funcs) Lookup(name string) (Func, bool) {
+	if fnc, ok := fs.companion(false)[name]; ok {
+		return fnc, true
+	}
+	fnc, ok := fs[name]
+	if !ok || name == companionName {
+		return nil, false
+	}
+	return fnc, true
+}
+
+var (
+	builtinFactories = BuiltinFactories()
+	// callFuncs caches the funcs created for calls like trimsuffix("%")
+	callFuncs sync.Map
+)
+
+// lookupFunc returns funcs[name]. If there is no such func and name is a call like trimsuffix("%"),
+// the func is created by the builtin factory.
+func lookupFunc(funcs Funcs, name string) (Func, error) {
+	if fnc, ok := funcs.Lookup(name); ok {
+		return fnc, nil
+	}
+	if fnc, ok := callFuncs.Load(name); ok {
+		return fnc.(Func), nil
+	}
+	factoryName, args, err := parseCall(name)
+	if err != nil || args == nil {
+		return nil, errors.Errorf("no such func %q", name)
+	}
+	factory, ok := builtinFactories[factoryName]
+	if !ok {
+		return nil, errors.Errorf("no such func %q", name)
+	}
+	fnc, err := factory(args...)
+	if err != nil {
+		return nil, errors.Wrapf(err, "create func %q", name)
+	}
+	callFuncs.Store(name, fnc)
+	return fnc, nil
+}
+
+// evalValue calls fnc with the value of a previous pipeline stage
+func evalValue(ctx context.Context, fnc Func, v any) (any, error) {
+	if vf, ok := fnc.(ValueFunc); ok {
+		return vf.EvalValue(ctx, v)
+	}
+	s, ok := v.(string)
+	if !ok {
+		s = fmt.Sprint(v)
+	}
+	return fnc.EvalContext(ctx, s)
+}
+
+// stringFunc wraps a string transformation
+func stringFunc(fnc func(s string) string) EvalFunc {
+	return func(s string) (any, error) {
+		return fnc(s), nil
+	}
+}
+
+// numberFunc wraps an arithmetic operation. Numbers and numeric strings are accepted, the result is a float64.
+func numberFunc(fnc func(f float64) (float64, error)) EvalValueFunc {
+	return func(v any) (any, error) {
+		var f float64
+		switch v := v.(type) {
+		case string:
+			var err error
+			f, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			rv := reflect.ValueOf(v)
+			switch {
+			case isInt(rv.Kind()):
+				f = float64(rv.Int())
+			case isUint(rv.Kind()):
+				f = float64(rv.Uint())
+			case isNumber(rv.Kind()):
+				f = rv.Float()
+			default:
+				return nil, errors.Errorf("%T is not a number", v)
+			}
+		}
+		return fnc(f)
+	}
+}
+
+func unquote(s string) (any, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	return strconv.Unquote(s)
+}
+
 func BuiltinFuncs() Funcs {
 	fs := Funcs{}
-	fs["string"] = func(s string) (any, error) {
+	fs.Add("string", func(s string) (any, error) {
 		return s, nil
-	}
-	fs["int"] = func(s string) (any, error) {
+	})
+	fs.Add("int", func(s string) (any, error) {
 		n, err := strconv.ParseInt(s, 10, 64)
 		return int(n), err
-	}
-	fs["float"] = func(s string) (any, error) {
+	})
+	fs.Add("float", func(s string) (any, error) {
 		return strconv.ParseFloat(s, 64)
-	}
-	fs["bool"] = func(s string) (any, error) {
+	})
+	fs.Add("bool", func(s string) (any, error) {
 		return strconv.ParseBool(s)
-	}
-	fs["[]string"] = func(s string) (any, error) {
+	})
+	fs.Add("[]string", func(s string) (any, error) {
 		return slices.Convert(strings.Split(s, ","), slices.TrimSpace)
-	}
-	fs["[]int"] = func(s string) (any, error) {
+	})
+	fs.Add("[]int", func(s string) (any, error) {
 		return slices.Convert(strings.Split(s, ","), slices.ParseInt)
-	}
-	fs["[]float"] = func(s string) (any, error) {
+	})
+	fs.Add("[]float", func(s string) (any, error) {
 		return slices.Convert(strings.Split(s, ","), slices.ParseFloat)
-	}
-	fs["[]bool"] = func(s string) (any, error) {
+	})
+	fs.Add("[]bool", func(s string) (any, error) {
 		return slices.Convert(strings.Split(s, ","), slices.ParseBool)
-	}
-	fs["byte"] = func(s string) (any, error) {
+	})
+	fs.Add("[]csv", func(s string) (any, error) {
+		r := csv.NewReader(strings.NewReader(s))
+		r.TrimLeadingSpace = true
+		return r.Read()
+	})
+	fs.Add("logfmt", func(s string) (any, error) {
+		return parseLogfmt(s)
+	})
+	fs.Add("kv", func(s string) (any, error) {
+		return parseKV(s, ",", "=")
+	})
+	fs.AddFunc("balanced", Balanced(EvalFunc(func(s string) (any, error) {
+		return s, nil
+	})))
+	fs.AddFunc("json", CaptureFunc{
+		CaptureFnc: jsonPrefix,
+		EvalFnc: func(s string) (any, error) {
+			if !json.Valid([]byte(s)) {
+				return nil, errors.Errorf("invalid json %q", s)
+			}
+			return json.RawMessage(s), nil
+		},
+	})
+	fs.Add("byte", func(s string) (any, error) {
 		if s == "" {
 			return nil, errors.Errorf("empty string")
 		}
 		return ([]byte(s))[0], nil
-	}
-	fs["[]byte"] = func(s string) (any, error) {
+	})
+	fs.Add("[]byte", func(s string) (any, error) {
 		return []byte(s), nil
-	}
+	})
+	fs.Add("time", func(s string) (any, error) {
+		return time.Parse(time.RFC3339Nano, s)
+	})
+
+	// transformations, e.g. for pipelines like {{level: trim | lower}}
+	fs.Add("trim", stringFunc(strings.TrimSpace))
+	fs.Add("lower", stringFunc(strings.ToLower))
+	fs.Add("upper", stringFunc(strings.ToUpper))
+	fs.Add("unquote", unquote)
+
+	// quote-aware funcs, which capture a quoted token even if it contains the next literal
+	fs.AddFunc("quoted", CaptureFunc{
+		CaptureFnc: quotedPrefix,
+		EvalFnc: func(s string) (any, error) {
+			return unquoteQuoted(s)
+		},
+	})
+	fs.AddFunc("goquoted", CaptureFunc{
+		CaptureFnc: goQuotedPrefix,
+		EvalFnc: func(s string) (any, error) {
+			return strconv.Unquote(s)
+		},
+	})
+	fs.AddFunc("[]quoted", CaptureFunc{
+		CaptureFnc: quotedListPrefix,
+		EvalFnc: func(s string) (any, error) {
+			return splitQuoted(s)
+		},
+	})
 
 	return fs
 }
+
+// FuncFactory creates a Func from arguments, e.g. a time func from a layout
+type FuncFactory func(args ...string) (Func, error)
+
+type Factories map[string]FuncFactory
+
+func BuiltinFactories() Factories {
+	fs := Factories{}
+	fs["time"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("time expects 1 arg (layout), got %d", len(args))
+		}
+		layout := args[0]
+		return EvalFunc(func(s string) (any, error) {
+			return time.Parse(layout, s)
+		}), nil
+	}
+	fs["int"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("int expects 1 arg (base), got %d", len(args))
+		}
+		base, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid base %q", args[0])
+		}
+		return EvalFunc(func(s string) (any, error) {
+			n, err := strconv.ParseInt(s, base, 64)
+			return int(n), err
+		}), nil
+	}
+	fs["split"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("split expects 1 arg (separator), got %d", len(args))
+		}
+		sep := args[0]
+		return EvalFunc(func(s string) (any, error) {
+			return slices.Convert(strings.Split(s, sep), slices.TrimSpace)
+		}), nil
+	}
+	fs["trim"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("trim expects 1 arg (cutset), got %d", len(args))
+		}
+		cutset := args[0]
+		return stringFunc(func(s string) string { return strings.Trim(s, cutset) }), nil
+	}
+	fs["trimprefix"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("trimprefix expects 1 arg (prefix), got %d", len(args))
+		}
+		prefix := args[0]
+		return stringFunc(func(s string) string { return strings.TrimPrefix(s, prefix) }), nil
+	}
+	fs["trimsuffix"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("trimsuffix expects 1 arg (suffix), got %d", len(args))
+		}
+		suffix := args[0]
+		return stringFunc(func(s string) string { return strings.TrimSuffix(s, suffix) }), nil
+	}
+	fs["replace"] = func(args ...string) (Func, error) {
+		if len(args) != 2 {
+			return nil, errors.Errorf("replace expects 2 args (old, new), got %d", len(args))
+		}
+		r := strings.NewReplacer(args[0], args[1])
+		return stringFunc(r.Replace), nil
+	}
+	fs["kv"] = func(args ...string) (Func, error) {
+		if len(args) != 2 || args[0] == "" || args[1] == "" {
+			return nil, errors.Errorf("kv expects 2 non-empty args (separator, assign), got %q", args)
+		}
+		sep, assign := args[0], args[1]
+		return EvalFunc(func(s string) (any, error) {
+			return parseKV(s, sep, assign)
+		}), nil
+	}
+	fs["mul"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("mul expects 1 arg (factor), got %d", len(args))
+		}
+		factor, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid factor %q", args[0])
+		}
+		return numberFunc(func(f float64) (float64, error) { return f * factor, nil }), nil
+	}
+	fs["div"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("div expects 1 arg (divisor), got %d", len(args))
+		}
+		divisor, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid divisor %q", args[0])
+		}
+		if divisor == 0 {
+			return nil, errors.Errorf("division by zero")
+		}
+		return numberFunc(func(f float64) (float64, error) { return f / divisor, nil }), nil
+	}
+	fs["add"] = func(args ...string) (Func, error) {
+		if len(args) != 1 {
+			return nil, errors.Errorf("add expects 1 arg (summand), got %d", len(args))
+		}
+		summand, err := strconv.ParseFloat(args[0], 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid summand %q", args[0])
+		}
+		return numberFunc(func(f float64) (float64, error) { return f + summand, nil }), nil
+	}
+	fs["enum"] = func(args ...string) (Func, error) {
+		if len(args) == 0 {
+			return nil, errors.Errorf("enum expects at least 1 arg")
+		}
+		values := append([]string{}, args...)
+		return EvalFunc(func(s string) (any, error) {
+			for _, v := range values {
+				if s == v {
+					return s, nil
+				}
+			}
+			return nil, errors.Errorf("%q is not one of %q", s, values)
+		}), nil
+	}
+	return fs
+}
@@ -0,0 +1,151 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	funcs := BuiltinFuncs()
+	funcs.AddValue("double", func(v any) (any, error) {
+		n, ok := v.(int)
+		if !ok {
+			return nil, fmt.Errorf("want int, got %T", v)
+		}
+		return 2 * n, nil
+	})
+	tests := []struct {
+		template string
+		in       string
+		fail     bool
+		params   []ResultItem
+	}{
+		{
+			template: `{{pct: trimsuffix("%") | float}}`,
+			in:       "12.5%",
+			params:   []ResultItem{{"pct", 12.5}},
+		},
+		{
+			template: `{{pct: trimsuffix("%") | float | div(100)}}`,
+			in:       "50%",
+			params:   []ResultItem{{"pct", 0.5}},
+		},
+		{
+			template: `[{{level: trim("[]") | lower | enum(debug, info, warn)}}]`,
+			in:       "[INFO]",
+			params:   []ResultItem{{"level", "info"}},
+		},
+		{
+			template: `{{level: upper}} {{msg: unquote | replace("_", " ")}}`,
+			in:       `warn "disk_is_full"`,
+			params:   []ResultItem{{"level", "WARN"}, {"msg", "disk is full"}},
+		},
+		{
+			template: `{{n: int | double | mul(1.5)|max=100}}`,
+			in:       "10",
+			params:   []ResultItem{{"n", 30.0}},
+		},
+		{
+			template: `{{n: int | double | mul(1.5)|max=100}}`,
+			in:       "40",
+			fail:     true,
+		},
+		{
+			template: `{{n: string | double}}`,
+			in:       "40",
+			fail:     true,
+		},
+		{
+			template: `{{n: int | nofunc}}`,
+			in:       "40",
+			fail:     true,
+		},
+		{
+			template: `{{n: int | replace("a")}}`,
+			in:       "40",
+			fail:     true,
+		},
+		{
+			template: `{{n: int(16) | add(1)}}`,
+			in:       "ff",
+			params:   []ResultItem{{"n", 256.0}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			tpl2, err := ParseTemplate("test", tpl.String())
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.String(), tpl2.String())
+
+			res, err := tpl.Eval(test.in, funcs)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+
+	for _, pattern := range []string{
+		`{{n: int|min=1 | double}}`,
+		`{{n: int | replace("a}}`,
+		`{{n: int | }}`,
+	} {
+		_, err := ParseTemplate("test", pattern)
+		noErrWhenErrExpected(t, err)
+	}
+}
+
+type ctxKey struct{}
+
+func TestFuncsCompanion(t *testing.T) {
+	// plain func literals are EvalFuncs
+	funcs := Funcs{
+		"id": func(s string) (any, error) {
+			return s, nil
+		},
+	}
+	funcs.AddContext("user", func(ctx context.Context, s string) (any, error) {
+		return fmt.Sprint(ctx.Value(ctxKey{}), ":", s), nil
+	})
+	tpl, err := ParseTemplate("test", "{{a: id}} {{b: user}}")
+	errWhenNoneExpected(t, err)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "bob")
+	res, err := tpl.EvalContext(ctx, "x y", funcs)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"a", "x"}, {"b", "bob:y"}}, res.Items)
+
+	// the name of a context func maps to an EvalFunc, which calls it with a background context
+	v, err := funcs["user"]("y")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, "<nil>:y", v)
+
+	// copies don't modify the original
+	cp := Funcs{}
+	for name, fnc := range funcs {
+		cp[name] = fnc
+	}
+	cp.AddContext("user", func(ctx context.Context, s string) (any, error) {
+		return "copy", nil
+	})
+	res, err = tpl.EvalContext(ctx, "x y", funcs)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"a", "x"}, {"b", "bob:y"}}, res.Items)
+	res, err = tpl.EvalContext(ctx, "x y", cp)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"a", "x"}, {"b", "copy"}}, res.Items)
+
+	// Add replaces a context func
+	funcs.Add("user", func(s string) (any, error) {
+		return "plain", nil
+	})
+	res, err = tpl.EvalContext(ctx, "x y", funcs)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"a", "x"}, {"b", "plain"}}, res.Items)
+	_, ok := funcs.Lookup(companionName)
+	assertEqual(t, false, ok)
+}
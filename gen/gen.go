@@ -0,0 +1,313 @@
+// Package gen generates Go structs and specialised, reflection-free parse functions from scan templates.
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/mazzegi/scan"
+	"github.com/pkg/errors"
+)
+
+type Config struct {
+	Package  string
+	TypeName string
+	Pattern  string
+}
+
+// builtin describes how the value of a builtin func is parsed in generated code.
+// conv is a format string with the captured string as %[1]s and the target field as %[2]s.
+type builtin struct {
+	typ     string
+	imports []string
+	conv    string
+}
+
+var builtins = map[string]builtin{
+	"string": {
+		typ:  "string",
+		conv: "%[2]s = %[1]s\n",
+	},
+	"int": {
+		typ:     "int",
+		imports: []string{"strconv"},
+		conv: `n, err := strconv.ParseInt(%[1]s, 10, 64)
+if err != nil {
+	return t, err
+}
+%[2]s = int(n)
+`,
+	},
+	"float": {
+		typ:     "float64",
+		imports: []string{"strconv"},
+		conv: `f, err := strconv.ParseFloat(%[1]s, 64)
+if err != nil {
+	return t, err
+}
+%[2]s = f
+`,
+	},
+	"bool": {
+		typ:     "bool",
+		imports: []string{"strconv"},
+		conv: `b, err := strconv.ParseBool(%[1]s)
+if err != nil {
+	return t, err
+}
+%[2]s = b
+`,
+	},
+	"[]string": {
+		typ: "[]string",
+		conv: `for _, e := range strings.Split(%[1]s, ",") {
+	%[2]s = append(%[2]s, strings.TrimSpace(e))
+}
+`,
+	},
+	"[]int": {
+		typ:     "[]int",
+		imports: []string{"strconv"},
+		conv: `for _, e := range strings.Split(%[1]s, ",") {
+	n, err := strconv.ParseInt(strings.TrimSpace(e), 10, 64)
+	if err != nil {
+		return t, err
+	}
+	%[2]s = append(%[2]s, int(n))
+}
+`,
+	},
+	"[]float": {
+		typ:     "[]float64",
+		imports: []string{"strconv"},
+		conv: `for _, e := range strings.Split(%[1]s, ",") {
+	f, err := strconv.ParseFloat(strings.TrimSpace(e), 64)
+	if err != nil {
+		return t, err
+	}
+	%[2]s = append(%[2]s, f)
+}
+`,
+	},
+	"[]bool": {
+		typ:     "[]bool",
+		imports: []string{"strconv"},
+		conv: `for _, e := range strings.Split(%[1]s, ",") {
+	b, err := strconv.ParseBool(strings.TrimSpace(e))
+	if err != nil {
+		return t, err
+	}
+	%[2]s = append(%[2]s, b)
+}
+`,
+	},
+	"byte": {
+		typ: "byte",
+		conv: `if %[1]s == "" {
+	return t, fmt.Errorf("empty string")
+}
+%[2]s = %[1]s[0]
+`,
+	},
+	"[]byte": {
+		typ:  "[]byte",
+		conv: "%[2]s = []byte(%[1]s)\n",
+	},
+	"time": {
+		typ:     "time.Time",
+		imports: []string{"time"},
+		conv: `tm, err := time.Parse(time.RFC3339Nano, %[1]s)
+if err != nil {
+	return t, err
+}
+%[2]s = tm
+`,
+	},
+}
+
+// Generate writes a struct type and a func Parse<TypeName>(line string) (<TypeName>, error) for the pattern in cfg.
+// Only builtin funcs are supported.
+func Generate(w io.Writer, cfg Config) error {
+	if cfg.Package == "" {
+		return errors.Errorf("empty package name")
+	}
+	if !isExported(cfg.TypeName) {
+		return errors.Errorf("type name %q is not an exported identifier", cfg.TypeName)
+	}
+	tpl, err := scan.ParseTemplate(cfg.TypeName, cfg.Pattern)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+	if tpl.Options().StrictLiterals {
+		return errors.Errorf("strict literals are not supported")
+	}
+
+	imports := map[string]bool{"fmt": true, "strings": true, "unicode": true}
+	var fields, body bytes.Buffer
+	fieldNames := map[string]string{}
+	items := tpl.Items()
+	for i, item := range items {
+		if !startsWithWhite(item) {
+			fmt.Fprintf(&body, "for pos < len(s) && s[pos] == ' ' {\n\tpos++\n}\n")
+		}
+		if _, ok := item.(scan.Skip); !ok {
+			fmt.Fprintf(&body, "if pos >= len(s) {\n\treturn t, fmt.Errorf(\"EOF\")\n}\n")
+		}
+		switch item := item.(type) {
+		case string:
+			fmt.Fprintf(&body, "if !strings.HasPrefix(s[pos:], %q) {\n\treturn t, fmt.Errorf(\"no match for string %%q\", %q)\n}\n", item, item)
+			fmt.Fprintf(&body, "pos += %d\n", len(item))
+		case scan.Whitespace:
+			fmt.Fprintf(&body, "if s[pos] != ' ' && s[pos] != '\\t' {\n\treturn t, fmt.Errorf(\"no match for whitespace\")\n}\n")
+			fmt.Fprintf(&body, "for pos < len(s) && (s[pos] == ' ' || s[pos] == '\\t') {\n\tpos++\n}\n")
+		case scan.Evaler:
+			if strings.ContainsAny(item.Name(), ".[]") {
+				return errors.Errorf("evaler %q: nested names are not supported", item.Name())
+			}
+			if len(item.Pipeline()) > 0 {
+				return errors.Errorf("evaler %q: pipelines are not supported", item.Name())
+			}
+			if len(item.Constraints()) > 0 {
+				return errors.Errorf("evaler %q: constraints are not supported", item.Name())
+			}
+			if _, ok := item.Default(); ok || item.Nullable() {
+				return errors.Errorf("evaler %q: defaults and nullable evalers are not supported", item.Name())
+			}
+			bi, ok := builtins[item.FuncName()]
+			if !ok {
+				return errors.Errorf("evaler %q: unsupported func %q", item.Name(), item.FuncName())
+			}
+			fieldName := exportedName(item.Name())
+			if fieldName == "" {
+				return errors.Errorf("evaler %q: cannot derive field name", item.Name())
+			}
+			if other, ok := fieldNames[fieldName]; ok {
+				return errors.Errorf("evalers %q and %q map to the same field %q", other, item.Name(), fieldName)
+			}
+			fieldNames[fieldName] = item.Name()
+			fmt.Fprintf(&fields, "%s %s `json:%q`\n", fieldName, bi.typ, item.Name())
+			for _, imp := range bi.imports {
+				imports[imp] = true
+			}
+
+			if i == len(items)-1 {
+				fmt.Fprintf(&body, "es = s[pos:]\n")
+			} else {
+				if err := writeFindNext(&body, items[i+1]); err != nil {
+					return errors.Wrapf(err, "evaler %q", item.Name())
+				}
+				fmt.Fprintf(&body, "es = s[pos : pos+idx]\n")
+			}
+			fmt.Fprintf(&body, "pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))\n")
+			fmt.Fprintf(&body, "es = strings.TrimSpace(es)\n")
+			fmt.Fprintf(&body, "{\n"+bi.conv+"}\n", "es", "t."+fieldName)
+			fmt.Fprintf(&body, "pos += len(es)\n")
+		case scan.Skip:
+			if i == len(items)-1 {
+				fmt.Fprintf(&body, "pos = len(s)\n")
+			} else {
+				if err := writeFindNext(&body, items[i+1]); err != nil {
+					return errors.Wrap(err, "skip")
+				}
+				fmt.Fprintf(&body, "pos += idx\n")
+			}
+		default:
+			return errors.Errorf("unsupported item %T", item)
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by scangen. DO NOT EDIT.\n")
+	fmt.Fprintf(&src, "\npackage %s\n\n", cfg.Package)
+	fmt.Fprintf(&src, "import (\n")
+	for _, imp := range []string{"fmt", "strconv", "strings", "time", "unicode"} {
+		if imports[imp] {
+			fmt.Fprintf(&src, "%q\n", imp)
+		}
+	}
+	fmt.Fprintf(&src, ")\n\n")
+	fmt.Fprintf(&src, "// %s is generated from the template pattern %q\n", cfg.TypeName, cfg.Pattern)
+	fmt.Fprintf(&src, "type %s struct {\n%s}\n\n", cfg.TypeName, fields.String())
+	fmt.Fprintf(&src, "// Parse%s parses line like the template pattern %q\n", cfg.TypeName, cfg.Pattern)
+	fmt.Fprintf(&src, "func Parse%s(line string) (%s, error) {\n", cfg.TypeName, cfg.TypeName)
+	fmt.Fprintf(&src, "var t %s\n", cfg.TypeName)
+	fmt.Fprintf(&src, "s := strings.TrimSpace(line)\n")
+	fmt.Fprintf(&src, "var pos int\n")
+	if strings.Contains(body.String(), "idx = ") {
+		fmt.Fprintf(&src, "var idx int\n")
+	}
+	if strings.Contains(body.String(), "es = ") {
+		fmt.Fprintf(&src, "var es string\n")
+	}
+	src.Write(body.Bytes())
+	fmt.Fprintf(&src, "return t, nil\n}\n")
+
+	bs, err := format.Source(src.Bytes())
+	if err != nil {
+		return errors.Wrapf(err, "format generated source\n%s", src.String())
+	}
+	_, err = w.Write(bs)
+	return err
+}
+
+// writeFindNext writes code, which sets idx to the position of the separator next relative to pos
+func writeFindNext(w io.Writer, next scan.Item) error {
+	switch next := next.(type) {
+	case string:
+		fmt.Fprintf(w, "idx = strings.Index(s[pos:], %q)\n", next)
+		fmt.Fprintf(w, "if idx < 0 {\n\treturn t, fmt.Errorf(\"no match for next %%q\", %q)\n}\n", next)
+	case scan.Whitespace:
+		fmt.Fprintf(w, "idx = strings.IndexAny(s[pos:], \" \\t\")\n")
+		fmt.Fprintf(w, "if idx < 0 {\n\treturn t, fmt.Errorf(\"no match for next whitespace\")\n}\n")
+	default:
+		return errors.Errorf("next is not a string")
+	}
+	return nil
+}
+
+func isExported(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if i == 0 && !unicode.IsUpper(r) {
+			return false
+		}
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// exportedName converts an evaler name like "x0" or "first_name" to a Go field name like "X0" or "FirstName"
+func exportedName(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var sb strings.Builder
+	for _, part := range parts {
+		rs := []rune(part)
+		rs[0] = unicode.ToUpper(rs[0])
+		sb.WriteString(string(rs))
+	}
+	name := sb.String()
+	if !isExported(name) {
+		return ""
+	}
+	return name
+}
+
+func startsWithWhite(item scan.Item) bool {
+	switch item := item.(type) {
+	case scan.Whitespace:
+		return true
+	case string:
+		return item != "" && unicode.IsSpace(rune(item[0]))
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package gen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	tests := []struct {
+		cfg    Config
+		fail   bool
+		golden string
+	}{
+		{
+			cfg: Config{
+				Package:  "aoc",
+				TypeName: "Command",
+				Pattern:  "{{action: string}} x={{x0: int}}..{{x1: int}},y={{y0: int}}..{{y1: int}},z={{z0: int}}..{{z1: int}}",
+			},
+			golden: "internal/aoc/command_gen.go",
+		},
+		{
+			cfg:  Config{Package: "aoc", TypeName: "Foo", Pattern: "{{a: custom}}"},
+			fail: true,
+		},
+		{
+			cfg:  Config{Package: "aoc", TypeName: "foo", Pattern: "{{a: int}}"},
+			fail: true,
+		},
+		{
+			cfg:  Config{Package: "aoc", TypeName: "Foo", Pattern: "{{a_b: int}}-{{a b: int}}"},
+			fail: true,
+		},
+		{
+			cfg:  Config{Package: "", TypeName: "Foo", Pattern: "{{a: int}}"},
+			fail: true,
+		},
+		{
+			cfg:  Config{Package: "aoc", TypeName: "Foo", Pattern: "{{a: int|min=1}}"},
+			fail: true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := Generate(buf, test.cfg)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail")
+			}
+			golden, err := os.ReadFile(test.golden)
+			if err != nil {
+				t.Fatalf("read golden file: %v", err)
+			}
+			if !bytes.Equal(golden, buf.Bytes()) {
+				t.Fatalf("generated code differs from %s. run go generate ./...", test.golden)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Code generated by scangen. DO NOT EDIT.
+
+package aoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Access is generated from the template pattern "{{ip: string}} - {{-}} [{{*}}] \"{{method: string}} {{path: string}} {{*}}\" {{status: int}} {{*}}"
+type Access struct {
+	Ip     string `json:"ip"`
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+// ParseAccess parses line like the template pattern "{{ip: string}} - {{-}} [{{*}}] \"{{method: string}} {{path: string}} {{*}}\" {{status: int}} {{*}}"
+func ParseAccess(line string) (Access, error) {
+	var t Access
+	s := strings.TrimSpace(line)
+	var pos int
+	var idx int
+	var es string
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "-")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "-")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Ip = es
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "-") {
+		return t, fmt.Errorf("no match for string %q", "-")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	idx = strings.Index(s[pos:], "[")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "[")
+	}
+	pos += idx
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "[") {
+		return t, fmt.Errorf("no match for string %q", "[")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	idx = strings.Index(s[pos:], "] \"")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "] \"")
+	}
+	pos += idx
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "] \"") {
+		return t, fmt.Errorf("no match for string %q", "] \"")
+	}
+	pos += 3
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.IndexAny(s[pos:], " \t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next whitespace")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Method = es
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if s[pos] != ' ' && s[pos] != '\t' {
+		return t, fmt.Errorf("no match for whitespace")
+	}
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.IndexAny(s[pos:], " \t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next whitespace")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Path = es
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if s[pos] != ' ' && s[pos] != '\t' {
+		return t, fmt.Errorf("no match for whitespace")
+	}
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	idx = strings.Index(s[pos:], "\"")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "\"")
+	}
+	pos += idx
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "\"") {
+		return t, fmt.Errorf("no match for string %q", "\"")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.IndexAny(s[pos:], " \t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next whitespace")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Status = int(n)
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if s[pos] != ' ' && s[pos] != '\t' {
+		return t, fmt.Errorf("no match for whitespace")
+	}
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	pos = len(s)
+	return t, nil
+}
@@ -0,0 +1,230 @@
+// Code generated by scangen. DO NOT EDIT.
+
+package aoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Command is generated from the template pattern "{{action: string}} x={{x0: int}}..{{x1: int}},y={{y0: int}}..{{y1: int}},z={{z0: int}}..{{z1: int}}"
+type Command struct {
+	Action string `json:"action"`
+	X0     int    `json:"x0"`
+	X1     int    `json:"x1"`
+	Y0     int    `json:"y0"`
+	Y1     int    `json:"y1"`
+	Z0     int    `json:"z0"`
+	Z1     int    `json:"z1"`
+}
+
+// ParseCommand parses line like the template pattern "{{action: string}} x={{x0: int}}..{{x1: int}},y={{y0: int}}..{{y1: int}},z={{z0: int}}..{{z1: int}}"
+func ParseCommand(line string) (Command, error) {
+	var t Command
+	s := strings.TrimSpace(line)
+	var pos int
+	var idx int
+	var es string
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "x=")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "x=")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Action = es
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "x=") {
+		return t, fmt.Errorf("no match for string %q", "x=")
+	}
+	pos += 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "..")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "..")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.X0 = int(n)
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "..") {
+		return t, fmt.Errorf("no match for string %q", "..")
+	}
+	pos += 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], ",y=")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", ",y=")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.X1 = int(n)
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], ",y=") {
+		return t, fmt.Errorf("no match for string %q", ",y=")
+	}
+	pos += 3
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "..")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "..")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Y0 = int(n)
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "..") {
+		return t, fmt.Errorf("no match for string %q", "..")
+	}
+	pos += 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], ",z=")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", ",z=")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Y1 = int(n)
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], ",z=") {
+		return t, fmt.Errorf("no match for string %q", ",z=")
+	}
+	pos += 3
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "..")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "..")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Z0 = int(n)
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "..") {
+		return t, fmt.Errorf("no match for string %q", "..")
+	}
+	pos += 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	es = s[pos:]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Z1 = int(n)
+	}
+	pos += len(es)
+	return t, nil
+}
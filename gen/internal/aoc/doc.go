@@ -0,0 +1,7 @@
+// Package aoc contains a parser generated by scangen, which is tested against the reflection-based scan.Template.
+package aoc
+
+//go:generate go run ../../../cmd/scangen -type Command -pattern "{{action: string}} x={{x0: int}}..{{x1: int}},y={{y0: int}}..{{y1: int}},z={{z0: int}}..{{z1: int}}" -o command_gen.go
+//go:generate go run ../../../cmd/scangen -type Sample -pattern "{{name: string}}: ratio={{ratio: float}}, ok={{ok: bool}} at {{at: time}} [{{tags: []string}}] ({{nums: []int}}) / {{fs: []float}} / {{bs: []bool}} / {{b: byte}} / {{raw: []byte}}" -o sample_gen.go
+//go:generate go run ../../../cmd/scangen -type Process -pattern "{{user: string}}{{_}}{{pid: int}} {{cpu: float}}{{\\t}}{{cmd: string}}" -o process_gen.go
+//go:generate go run ../../../cmd/scangen -type Access -pattern "{{ip: string}} - {{-}} [{{*}}] \"{{method: string}} {{path: string}} {{*}}\" {{status: int}} {{*}}" -o access_gen.go
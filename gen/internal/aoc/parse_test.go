@@ -0,0 +1,94 @@
+package aoc
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/mazzegi/scan"
+)
+
+func assertSameAsTemplate[T any](t *testing.T, pattern string, parse func(string) (T, error), inputs []string) {
+	tpl, err := scan.ParseTemplate("test", pattern)
+	if err != nil {
+		t.Fatalf("parse template: %v", err)
+	}
+	funcs := scan.BuiltinFuncs()
+	for i, in := range inputs {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			var want T
+			res, wantErr := tpl.Eval(in, funcs)
+			if wantErr == nil {
+				wantErr = res.Decode(&want)
+			}
+			have, haveErr := parse(in)
+			if (wantErr == nil) != (haveErr == nil) {
+				t.Fatalf("error: want %v, have %v", wantErr, haveErr)
+			}
+			if wantErr != nil {
+				return
+			}
+			if !reflect.DeepEqual(want, have) {
+				t.Fatalf("want %+v, have %+v", want, have)
+			}
+		})
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	assertSameAsTemplate(t,
+		"{{action: string}} x={{x0: int}}..{{x1: int}},y={{y0: int}}..{{y1: int}},z={{z0: int}}..{{z1: int}}",
+		ParseCommand,
+		[]string{
+			"on x=-46..2,y=-26..20,z=-39..5",
+			"  off x=-37..-22,y=24..41,z=20..38  ",
+			"on x= 1 .. 2 ,y=3..4,z=5..6",
+			"on x=1..2,y=3..4,z=5..",
+			"on x=1..2,y=3..4",
+			"on x=1..b,y=3..4,z=5..6",
+			"on y=1..2,x=3..4,z=5..6",
+			"",
+		})
+}
+
+func TestParseSample(t *testing.T) {
+	assertSameAsTemplate(t,
+		"{{name: string}}: ratio={{ratio: float}}, ok={{ok: bool}} at {{at: time}} [{{tags: []string}}] ({{nums: []int}}) / {{fs: []float}} / {{bs: []bool}} / {{b: byte}} / {{raw: []byte}}",
+		ParseSample,
+		[]string{
+			"foo: ratio=0.5, ok=true at 2024-01-01T10:00:00Z [a, b,c] (1,2, 3) / 1.5,2e3 / true,false / x / raw bytes",
+			"bar baz: ratio=-1, ok=0 at 2024-01-01T10:00:00.5+02:00 [] (7) / 0 / 1 / yz / r",
+			"foo: ratio=0.5, ok=true at 2024-01-01 [a] (1) / 1 / true / x / r",
+			"foo: ratio=0.5, ok=true at 2024-01-01T10:00:00Z [a] (1,x) / 1 / true / x / r",
+			"foo: ratio=0.5, ok=true at 2024-01-01T10:00:00Z [a] (1) / 1 / true /  / r",
+		})
+}
+
+func TestParseProcess(t *testing.T) {
+	assertSameAsTemplate(t,
+		`{{user: string}}{{_}}{{pid: int}} {{cpu: float}}{{\t}}{{cmd: string}}`,
+		ParseProcess,
+		[]string{
+			"root 1 0.5\t/sbin/init",
+			"alice\t \t42 12.25\tgo run ./cmd/scan -t x",
+			"bob  7 -1\t",
+			"bob7 1.5\tsh",
+			"bob 7 1.5 sh",
+			"bob x 1.5\tsh",
+			"bob 7 fast\tsh",
+			"",
+		})
+}
+
+func TestParseAccess(t *testing.T) {
+	assertSameAsTemplate(t,
+		`{{ip: string}} - {{-}} [{{*}}] "{{method: string}} {{path: string}} {{*}}" {{status: int}} {{*}}`,
+		ParseAccess,
+		[]string{
+			`127.0.0.1 - frank [10/Oct/2000:13:55:36 -0700] "GET /apache_pb.gif HTTP/1.0" 200 2326`,
+			`10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "POST /login HTTP/1.1" 302`,
+			`10.0.0.1 - - [] "POST /login HTTP/1.1" 302 -`,
+			`10.0.0.1 - - [10/Oct/2000:13:55:36 -0700] "POST /login HTTP/1.1" ok 1`,
+			`10.0.0.1 - - 10/Oct/2000:13:55:36 -0700 "POST /login HTTP/1.1" 200 1`,
+		})
+}
@@ -0,0 +1,125 @@
+// Code generated by scangen. DO NOT EDIT.
+
+package aoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Process is generated from the template pattern "{{user: string}}{{_}}{{pid: int}} {{cpu: float}}{{\\t}}{{cmd: string}}"
+type Process struct {
+	User string  `json:"user"`
+	Pid  int     `json:"pid"`
+	Cpu  float64 `json:"cpu"`
+	Cmd  string  `json:"cmd"`
+}
+
+// ParseProcess parses line like the template pattern "{{user: string}}{{_}}{{pid: int}} {{cpu: float}}{{\\t}}{{cmd: string}}"
+func ParseProcess(line string) (Process, error) {
+	var t Process
+	s := strings.TrimSpace(line)
+	var pos int
+	var idx int
+	var es string
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.IndexAny(s[pos:], " \t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next whitespace")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.User = es
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if s[pos] != ' ' && s[pos] != '\t' {
+		return t, fmt.Errorf("no match for whitespace")
+	}
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.IndexAny(s[pos:], " \t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next whitespace")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		n, err := strconv.ParseInt(es, 10, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Pid = int(n)
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if s[pos] != ' ' && s[pos] != '\t' {
+		return t, fmt.Errorf("no match for whitespace")
+	}
+	for pos < len(s) && (s[pos] == ' ' || s[pos] == '\t') {
+		pos++
+	}
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "\t")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "\t")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		f, err := strconv.ParseFloat(es, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Cpu = f
+	}
+	pos += len(es)
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "\t") {
+		return t, fmt.Errorf("no match for string %q", "\t")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	es = s[pos:]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Cmd = es
+	}
+	pos += len(es)
+	return t, nil
+}
@@ -0,0 +1,326 @@
+// Code generated by scangen. DO NOT EDIT.
+
+package aoc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Sample is generated from the template pattern "{{name: string}}: ratio={{ratio: float}}, ok={{ok: bool}} at {{at: time}} [{{tags: []string}}] ({{nums: []int}}) / {{fs: []float}} / {{bs: []bool}} / {{b: byte}} / {{raw: []byte}}"
+type Sample struct {
+	Name  string    `json:"name"`
+	Ratio float64   `json:"ratio"`
+	Ok    bool      `json:"ok"`
+	At    time.Time `json:"at"`
+	Tags  []string  `json:"tags"`
+	Nums  []int     `json:"nums"`
+	Fs    []float64 `json:"fs"`
+	Bs    []bool    `json:"bs"`
+	B     byte      `json:"b"`
+	Raw   []byte    `json:"raw"`
+}
+
+// ParseSample parses line like the template pattern "{{name: string}}: ratio={{ratio: float}}, ok={{ok: bool}} at {{at: time}} [{{tags: []string}}] ({{nums: []int}}) / {{fs: []float}} / {{bs: []bool}} / {{b: byte}} / {{raw: []byte}}"
+func ParseSample(line string) (Sample, error) {
+	var t Sample
+	s := strings.TrimSpace(line)
+	var pos int
+	var idx int
+	var es string
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], ": ratio=")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", ": ratio=")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Name = es
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], ": ratio=") {
+		return t, fmt.Errorf("no match for string %q", ": ratio=")
+	}
+	pos += 8
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], ", ok=")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", ", ok=")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		f, err := strconv.ParseFloat(es, 64)
+		if err != nil {
+			return t, err
+		}
+		t.Ratio = f
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], ", ok=") {
+		return t, fmt.Errorf("no match for string %q", ", ok=")
+	}
+	pos += 5
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "at")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "at")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		b, err := strconv.ParseBool(es)
+		if err != nil {
+			return t, err
+		}
+		t.Ok = b
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "at") {
+		return t, fmt.Errorf("no match for string %q", "at")
+	}
+	pos += 2
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "[")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "[")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		tm, err := time.Parse(time.RFC3339Nano, es)
+		if err != nil {
+			return t, err
+		}
+		t.At = tm
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "[") {
+		return t, fmt.Errorf("no match for string %q", "[")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "] (")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "] (")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		for _, e := range strings.Split(es, ",") {
+			t.Tags = append(t.Tags, strings.TrimSpace(e))
+		}
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "] (") {
+		return t, fmt.Errorf("no match for string %q", "] (")
+	}
+	pos += 3
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], ") /")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", ") /")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		for _, e := range strings.Split(es, ",") {
+			n, err := strconv.ParseInt(strings.TrimSpace(e), 10, 64)
+			if err != nil {
+				return t, err
+			}
+			t.Nums = append(t.Nums, int(n))
+		}
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], ") /") {
+		return t, fmt.Errorf("no match for string %q", ") /")
+	}
+	pos += 3
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "/")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "/")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		for _, e := range strings.Split(es, ",") {
+			f, err := strconv.ParseFloat(strings.TrimSpace(e), 64)
+			if err != nil {
+				return t, err
+			}
+			t.Fs = append(t.Fs, f)
+		}
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "/") {
+		return t, fmt.Errorf("no match for string %q", "/")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "/")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "/")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		for _, e := range strings.Split(es, ",") {
+			b, err := strconv.ParseBool(strings.TrimSpace(e))
+			if err != nil {
+				return t, err
+			}
+			t.Bs = append(t.Bs, b)
+		}
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "/") {
+		return t, fmt.Errorf("no match for string %q", "/")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	idx = strings.Index(s[pos:], "/")
+	if idx < 0 {
+		return t, fmt.Errorf("no match for next %q", "/")
+	}
+	es = s[pos : pos+idx]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		if es == "" {
+			return t, fmt.Errorf("empty string")
+		}
+		t.B = es[0]
+	}
+	pos += len(es)
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	if !strings.HasPrefix(s[pos:], "/") {
+		return t, fmt.Errorf("no match for string %q", "/")
+	}
+	pos += 1
+	for pos < len(s) && s[pos] == ' ' {
+		pos++
+	}
+	if pos >= len(s) {
+		return t, fmt.Errorf("EOF")
+	}
+	es = s[pos:]
+	pos += len(es) - len(strings.TrimLeftFunc(es, unicode.IsSpace))
+	es = strings.TrimSpace(es)
+	{
+		t.Raw = []byte(es)
+	}
+	pos += len(es)
+	return t, nil
+}
@@ -0,0 +1,276 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// InferredField describes a variable field found by Infer
+type InferredField struct {
+	Name string
+	Func string
+	// Confidence is the share of sample values, which support the guessed func (0..1). For the string fallback,
+	// which every value supports, it is 1 minus the share accepted by the best typed func, e.g. 0.25, if 3 of 4 values are ints.
+	Confidence float64
+	Values     []string
+}
+
+type Inference struct {
+	Template *Template
+	Pattern  string
+	Fields   []InferredField
+}
+
+// inferFuncs are tried in order, the first one accepting all values of a field wins
+var inferFuncs = []string{"int", "float", "bool", "time", "[]int", "[]float", "[]bool"}
+
+// Infer aligns a set of sample lines, identifies constant literals and variable fields, and guesses the builtin func for each field.
+// It is a heuristic - the result should be reviewed before it is used.
+func Infer(samples []string) (*Inference, error) {
+	var tokenized [][]string
+	for _, s := range samples {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		tokenized = append(tokenized, tokenize(s))
+	}
+	if len(tokenized) < 2 {
+		return nil, errors.Errorf("need at least 2 non-empty samples, got %d", len(tokenized))
+	}
+
+	common := tokenized[0]
+	for _, toks := range tokenized[1:] {
+		common = lcs(common, toks)
+	}
+
+	// for each sample, the text between consecutive common tokens. regions[i][j] is the text of sample j in front of common[i].
+	// the last region is the text behind the last common token.
+	regions := make([][]string, len(common)+1)
+	for i := range regions {
+		regions[i] = make([]string, len(tokenized))
+	}
+	for j, toks := range tokenized {
+		var pos int
+		for i, c := range common {
+			var sb strings.Builder
+			for toks[pos] != c {
+				sb.WriteString(toks[pos])
+				pos++
+			}
+			regions[i][j] = sb.String()
+			pos++
+		}
+		regions[len(common)][j] = strings.Join(toks[pos:], "")
+	}
+
+	// build alternating sequence of literals and fields
+	type part struct {
+		literal string
+		values  []string
+		isField bool
+	}
+	var parts []part
+	addLiteral := func(s string) {
+		if len(parts) > 0 && !parts[len(parts)-1].isField {
+			parts[len(parts)-1].literal += s
+			return
+		}
+		parts = append(parts, part{literal: s})
+	}
+	addField := func(values []string) {
+		// a field following a field separated by whitespace only, cannot be expressed. so merge them.
+		if n := len(parts); n >= 2 && parts[n-2].isField && strings.TrimSpace(parts[n-1].literal) == "" {
+			ws := parts[n-1].literal
+			parts = parts[:n-1]
+			for j := range values {
+				parts[n-2].values[j] += ws + values[j]
+			}
+			return
+		}
+		parts = append(parts, part{values: values, isField: true})
+	}
+	for i := range regions {
+		empty := true
+		for _, v := range regions[i] {
+			if v != "" {
+				empty = false
+				break
+			}
+		}
+		if !empty {
+			addField(append([]string{}, regions[i]...))
+		}
+		if i < len(common) {
+			addLiteral(common[i])
+		}
+	}
+
+	inf := &Inference{}
+	var sb strings.Builder
+	names := map[string]int{}
+	for i, p := range parts {
+		if !p.isField {
+			// spaces around literals are kept as they are, the non-strict parser skips them anyway
+			sb.WriteString(escapeLiteral(p.literal, ParseOptions{StrictLiterals: true}))
+			continue
+		}
+		var prev string
+		if i > 0 {
+			prev = parts[i-1].literal
+		}
+		name := inferName(prev, len(inf.Fields)+1)
+		names[name]++
+		if n := names[name]; n > 1 {
+			name = fmt.Sprintf("%s%d", name, n)
+		}
+		values := make([]string, len(p.values))
+		for j, v := range p.values {
+			values[j] = strings.TrimSpace(v)
+		}
+		fnc, conf := inferFunc(values)
+		inf.Fields = append(inf.Fields, InferredField{
+			Name:       name,
+			Func:       fnc,
+			Confidence: conf,
+			Values:     values,
+		})
+		sb.WriteString(fmt.Sprintf("{{%s: %s}}", name, fnc))
+	}
+	inf.Pattern = sb.String()
+
+	tpl, err := ParseTemplate("inferred", inf.Pattern)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse inferred pattern %q", inf.Pattern)
+	}
+	funcs := BuiltinFuncs()
+	for _, s := range samples {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if _, err := tpl.Eval(s, funcs); err != nil {
+			return nil, errors.Wrapf(err, "inferred pattern %q doesn't match sample %q", inf.Pattern, s)
+		}
+	}
+	inf.Template = tpl
+	return inf, nil
+}
+
+// inferFunc returns the first typed func accepting all values or string with 1 minus the best share of a typed func
+func inferFunc(values []string) (string, float64) {
+	funcs := BuiltinFuncs()
+	var best float64
+	for _, name := range inferFuncs {
+		var ok int
+		for _, v := range values {
+			if _, err := funcs[name].EvalContext(context.Background(), v); err == nil {
+				ok++
+			}
+		}
+		share := float64(ok) / float64(len(values))
+		if share == 1 {
+			return name, 1
+		}
+		if share > best {
+			best = share
+		}
+	}
+	return "string", 1 - best
+}
+
+func inferName(prevLiteral string, idx int) string {
+	var word []rune
+	rs := []rune(prevLiteral)
+	i := len(rs) - 1
+	for i >= 0 && !isWordRune(rs[i]) {
+		i--
+	}
+	for i >= 0 && isWordRune(rs[i]) {
+		word = append([]rune{unicode.ToLower(rs[i])}, word...)
+		i--
+	}
+	if len(word) == 0 || unicode.IsDigit(word[0]) {
+		return fmt.Sprintf("field%d", idx)
+	}
+	return string(word)
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+var (
+	timeTokenRx   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`)
+	numberTokenRx = regexp.MustCompile(`^[-+]?\d+(\.\d+)?([eE][-+]?\d+)?`)
+)
+
+// tokenize splits s into timestamps, numbers, words and single other runes
+func tokenize(s string) []string {
+	var toks []string
+	var prev rune
+	for pos := 0; pos < len(s); {
+		rest := s[pos:]
+		var tok string
+		r, size := utf8.DecodeRuneInString(rest)
+		switch {
+		case timeTokenRx.MatchString(rest):
+			tok = timeTokenRx.FindString(rest)
+		case (unicode.IsDigit(r) || ((r == '-' || r == '+') && !isWordRune(prev))) && numberTokenRx.MatchString(rest):
+			tok = numberTokenRx.FindString(rest)
+		case isWordRune(r):
+			end := strings.IndexFunc(rest, func(r rune) bool { return !isWordRune(r) })
+			if end < 0 {
+				end = len(rest)
+			}
+			tok = rest[:end]
+		default:
+			tok = rest[:size]
+		}
+		toks = append(toks, tok)
+		pos += len(tok)
+		prev, _ = utf8.DecodeLastRuneInString(tok)
+	}
+	return toks
+}
+
+// lcs returns the longest common subsequence of a and b
+func lcs(a, b []string) []string {
+	n, m := len(a), len(b)
+	tab := make([][]int, n+1)
+	for i := range tab {
+		tab[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				tab[i][j] = tab[i+1][j+1] + 1
+			case tab[i+1][j] >= tab[i][j+1]:
+				tab[i][j] = tab[i+1][j]
+			default:
+				tab[i][j] = tab[i][j+1]
+			}
+		}
+	}
+	var res []string
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i] == b[j]:
+			res = append(res, a[i])
+			i++
+			j++
+		case tab[i+1][j] >= tab[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+package scan
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestInfer(t *testing.T) {
+	tests := []struct {
+		samples []string
+		fail    bool
+		pattern string
+		fields  []InferredField
+	}{
+		{
+			samples: []string{"only one"},
+			fail:    true,
+		},
+		{
+			samples: []string{
+				"on x=-46..2,y=-26..20",
+				"off x=0..44,y=-44..0",
+				"on x=-44..10,y=-20..28",
+			},
+			pattern: "{{field1: string}} x={{x: int}}..{{field3: int}},y={{y: int}}..{{field5: int}}",
+			fields: []InferredField{
+				{"field1", "string", 1, []string{"on", "off", "on"}},
+				{"x", "int", 1, []string{"-46", "0", "-44"}},
+				{"field3", "int", 1, []string{"2", "44", "10"}},
+				{"y", "int", 1, []string{"-26", "-44", "-20"}},
+				{"field5", "int", 1, []string{"20", "0", "28"}},
+			},
+		},
+		{
+			samples: []string{
+				"2024-01-01T10:00:00Z level=info took 1.5 ok=true",
+				"2024-01-02T11:30:00+02:00 level=warn took 3 ok=false",
+				"",
+				"2024-01-03T00:00:00.123Z level=info took 0.25 ok=true",
+			},
+			pattern: "{{field1: time}} level={{level: string}} took {{took: float}} ok={{ok: bool}}",
+			fields: []InferredField{
+				{"field1", "time", 1, []string{"2024-01-01T10:00:00Z", "2024-01-02T11:30:00+02:00", "2024-01-03T00:00:00.123Z"}},
+				{"level", "string", 1, []string{"info", "warn", "info"}},
+				{"took", "float", 1, []string{"1.5", "3", "0.25"}},
+				{"ok", "bool", 1, []string{"true", "false", "true"}},
+			},
+		},
+		{
+			samples: []string{
+				"ids: [1,2,3]",
+				"ids: [4]",
+				"ids: [x,6]",
+				"ids: [8,9]",
+			},
+			pattern: "ids: [{{ids: string}}]",
+			fields: []InferredField{
+				{"ids", "string", 0.25, []string{"1,2,3", "4", "x,6", "8,9"}},
+			},
+		},
+		{
+			samples: []string{
+				`{{a}} \ 1`,
+				`{{b}} \ 22`,
+			},
+			pattern: `\{{{{field1: string}}\}} \\ {{field2: int}}`,
+			fields: []InferredField{
+				{"field1", "string", 1, []string{"a", "b"}},
+				{"field2", "int", 1, []string{"1", "22"}},
+			},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			inf, err := Infer(test.samples)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail, but got %q", inf.Pattern)
+			}
+			assertEqual(t, test.pattern, inf.Pattern)
+			assertEqual(t, test.fields, inf.Fields)
+			if inf.Template == nil {
+				t.Fatalf("expect template")
+			}
+			for _, sample := range test.samples {
+				if sample == "" {
+					continue
+				}
+				_, err := inf.Template.Eval(sample, BuiltinFuncs())
+				errWhenNoneExpected(t, err)
+			}
+		})
+	}
+}
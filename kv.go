@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// parseLogfmt parses pairs like level=info msg="started" dur=3ms. Values are either bare or quoted in Go syntax.
+// A key without a value maps to an empty string.
+func parseLogfmt(s string) (map[string]string, error) {
+	m := map[string]string{}
+	rest := s
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return m, nil
+		}
+		end := strings.IndexAny(rest, "= \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		key := rest[:end]
+		if key == "" {
+			return nil, errors.Errorf("missing key at %q", rest)
+		}
+		rest = rest[end:]
+		if !strings.HasPrefix(rest, "=") {
+			m[key] = ""
+			continue
+		}
+		rest = rest[1:]
+		if strings.HasPrefix(rest, `"`) {
+			qs, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, errors.Errorf("key %q: invalid quoted value %s", key, rest)
+			}
+			m[key], _ = strconv.Unquote(qs)
+			rest = rest[len(qs):]
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, errors.Errorf("key %q: unexpected text %q after quoted value", key, rest)
+			}
+			continue
+		}
+		end = strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		m[key] = rest[:end]
+		rest = rest[end:]
+	}
+}
+
+// parseKV parses pairs like a=1, b="x, y" separated by sep, where keys and values are separated by assign.
+// Values may be quoted like the quoted func accepts them.
+func parseKV(s string, sep, assign string) (map[string]string, error) {
+	m := map[string]string{}
+	rest := s
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			return m, nil
+		}
+		end := strings.Index(rest, sep)
+		if end < 0 {
+			end = len(rest)
+		}
+		key, _, ok := strings.Cut(rest[:end], assign)
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, errors.Errorf("missing key at %q", rest)
+		}
+		if !ok {
+			m[key] = ""
+			rest = strings.TrimPrefix(rest[end:], sep)
+			continue
+		}
+		rest = strings.TrimLeft(rest[strings.Index(rest, assign)+len(assign):], " \t")
+		if n, ok := quotedPrefix(rest); ok {
+			m[key] = unquoteToken(rest[:n])
+			rest = strings.TrimLeft(rest[n:], " \t")
+			if rest != "" && !strings.HasPrefix(rest, sep) {
+				return nil, errors.Errorf("key %q: unexpected text %q after quoted value", key, rest)
+			}
+			rest = strings.TrimPrefix(rest, sep)
+			continue
+		}
+		end = strings.Index(rest, sep)
+		if end < 0 {
+			end = len(rest)
+		}
+		m[key] = strings.TrimSpace(rest[:end])
+		rest = strings.TrimPrefix(rest[end:], sep)
+	}
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestLogfmtAndKV(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template string
+		in       string
+		fail     bool
+		params   []ResultItem
+	}{
+		{
+			template: "{{fields: logfmt}}",
+			in:       `level=info msg="started \"api\"" dur=3ms debug`,
+			params:   []ResultItem{{"fields", map[string]string{"level": "info", "msg": `started "api"`, "dur": "3ms", "debug": ""}}},
+		},
+		{
+			template: "{{fields: logfmt}}",
+			in:       `msg="unterminated`,
+			fail:     true,
+		},
+		{
+			template: "{{fields: logfmt}}",
+			in:       `msg="a"b`,
+			fail:     true,
+		},
+		{
+			template: "{{fields: kv}}",
+			in:       `a=1, b = "x, y", c='it''s', d`,
+			params:   []ResultItem{{"fields", map[string]string{"a": "1", "b": "x, y", "c": "it's", "d": ""}}},
+		},
+		{
+			template: `{{fields: kv(";", ":")}}`,
+			in:       `a: 1; b: "x; y"`,
+			params:   []ResultItem{{"fields", map[string]string{"a": "1", "b": "x; y"}}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			res, err := tpl.Eval(test.in, funcs)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
+
+func TestDecodeLogfmt(t *testing.T) {
+	type entry struct {
+		At     time.Time     `json:"at"`
+		Level  string        `json:"level"`
+		Dur    time.Duration `json:"dur"`
+		Status int           `json:"status"`
+		Bytes  *uint16       `json:"bytes"`
+		Cached bool          `json:"cached"`
+		Extra  map[string]string
+	}
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{at: time}} [{{.: logfmt}}]: {{extra: logfmt}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval(`2024-01-01T10:00:00Z [level=info dur=1.5s status=200 bytes=512 cached=true other=x]: a=1`, funcs)
+	errWhenNoneExpected(t, err)
+	var e entry
+	errWhenNoneExpected(t, res.Decode(&e))
+	bytes := uint16(512)
+	assertEqual(t, entry{
+		At:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		Level:  "info",
+		Dur:    1500 * time.Millisecond,
+		Status: 200,
+		Bytes:  &bytes,
+		Cached: true,
+		Extra:  map[string]string{"a": "1"},
+	}, e)
+
+	var m map[string]any
+	errWhenNoneExpected(t, res.Decode(&m))
+	assertEqual(t, "info", m["level"])
+
+	res, err = tpl.Eval(`2024-01-01T10:00:00Z [status=ok]: a=1`, funcs)
+	errWhenNoneExpected(t, err)
+	noErrWhenErrExpected(t, res.Decode(&e))
+	res, err = tpl.Eval(`2024-01-01T10:00:00Z [bytes=70000]: a=1`, funcs)
+	errWhenNoneExpected(t, err)
+	noErrWhenErrExpected(t, res.Decode(&e))
+}
@@ -2,34 +2,281 @@ package scan
 
 import (
 	"bufio"
+	"context"
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/pkg/errors"
 )
 
-func Lines[T any](pattern string, funcs Funcs, r io.Reader) ([]T, error) {
-	tpl, err := ParseTemplate("lines", pattern)
-	if err != nil {
-		return nil, errors.Wrap(err, "parse template")
+type Option func(c *config)
+
+type config struct {
+	split        bufio.SplitFunc
+	maxTokenSize int
+	workers      int
+	chunkSize    int
+	// comma and header are used by Records
+	comma  rune
+	header bool
+}
+
+func newConfig(opts ...Option) config {
+	c := config{
+		split:        bufio.ScanLines,
+		maxTokenSize: bufio.MaxScanTokenSize,
+		workers:      1,
+		chunkSize:    1024,
+		comma:        ',',
 	}
-	var ts []T
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
+
+// Split sets the split function used to tokenize the input into lines. Defaults to bufio.ScanLines.
+func Split(fnc bufio.SplitFunc) Option {
+	return func(c *config) {
+		c.split = fnc
+	}
+}
+
+// MaxTokenSize sets the maximum size of a single line. Defaults to bufio.MaxScanTokenSize. Values below 1 are ignored.
+func MaxTokenSize(n int) Option {
+	return func(c *config) {
+		if n < 1 {
+			return
+		}
+		c.maxTokenSize = n
+	}
+}
+
+// Parallel evaluates and decodes lines with n concurrent workers. Lines are read in chunks
+// and the results are emitted in the original order.
+func Parallel(n int) Option {
+	return func(c *config) {
+		if n < 1 {
+			n = 1
+		}
+		c.workers = n
+	}
+}
+
+// ChunkSize sets the number of lines which are read before they are processed in parallel. Defaults to 1024.
+func ChunkSize(n int) Option {
+	return func(c *config) {
+		if n < 1 {
+			n = 1
+		}
+		c.chunkSize = n
+	}
+}
+
+// Comma sets the field delimiter of records. Defaults to ','.
+func Comma(r rune) Option {
+	return func(c *config) {
+		c.comma = r
+	}
+}
+
+// Header makes Records read the first record as header, which binds evalers to columns by name
+func Header() Option {
+	return func(c *config) {
+		c.header = true
+	}
+}
+
+func (c config) newScanner(r io.Reader) *bufio.Scanner {
 	scanner := bufio.NewScanner(r)
+	initSize := 4096
+	if c.maxTokenSize < initSize {
+		initSize = c.maxTokenSize
+	}
+	scanner.Buffer(make([]byte, 0, initSize), c.maxTokenSize)
+	scanner.Split(c.split)
+	return scanner
+}
+
+type line struct {
+	no   int
+	text string
+}
+
+// scanLines calls fnc for each non-empty, trimmed line of r. If ctx can be cancelled, r is scanned in a separate
+// goroutine, so that a blocking read doesn't delay cancellation. scanLines returns without waiting for that goroutine:
+// a Read in progress is not interrupted and the goroutine exits, when it returns. r is not read any further then.
+func scanLines(ctx context.Context, r io.Reader, cfg config, fnc func(ln line) error) error {
+	if ctx.Done() == nil {
+		return scanLinesSync(r, cfg, fnc)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	lines := make(chan line)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(lines)
+		errc <- scanLinesSync(ctxReader{ctx: ctx, r: r}, cfg, func(ln line) error {
+			select {
+			case lines <- ln:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	var lineNo int
+	cancelled := func() error {
+		return errors.Wrapf(ctx.Err(), "cancelled at line %d", lineNo)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return cancelled()
+		case ln, ok := <-lines:
+			if !ok {
+				return <-errc
+			}
+			if ctx.Err() != nil {
+				return cancelled()
+			}
+			lineNo = ln.no
+			if err := fnc(ln); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ctxReader stops reading from r, when ctx is done
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r ctxReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func scanLinesSync(r io.Reader, cfg config, fnc func(ln line) error) error {
+	var lineNo int
+	scanner := cfg.newScanner(r)
 	for scanner.Scan() {
+		lineNo++
 		ln := strings.TrimSpace(scanner.Text())
 		if ln == "" {
 			continue
 		}
-		res, err := tpl.Eval(ln, funcs)
-		if err != nil {
-			return nil, errors.Wrapf(err, "eval line %q", ln)
-		}
-		var t T
-		err = res.Decode(&t)
-		if err != nil {
-			return nil, errors.Wrap(err, "decode")
+		if err := fnc(line{no: lineNo, text: ln}); err != nil {
+			return err
 		}
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "scan line %d", lineNo+1)
+	}
+	return nil
+}
+
+func decodeLine[T any](ctx context.Context, tpl *Template, funcs Funcs, ln line) (T, error) {
+	var t T
+	res, err := tpl.EvalContext(ctx, ln.text, funcs)
+	if err != nil {
+		return t, errors.Wrapf(err, "eval line %d %q", ln.no, ln.text)
+	}
+	err = res.Decode(&t)
+	if err != nil {
+		return t, errors.Wrapf(err, "decode line %d", ln.no)
+	}
+	return t, nil
+}
+
+func Lines[T any](pattern string, funcs Funcs, r io.Reader, opts ...Option) ([]T, error) {
+	return LinesContext[T](context.Background(), pattern, funcs, r, opts...)
+}
+
+// LinesContext is like Lines, but stops when ctx is done and passes ctx to context-aware funcs
+func LinesContext[T any](ctx context.Context, pattern string, funcs Funcs, r io.Reader, opts ...Option) ([]T, error) {
+	var ts []T
+	err := EachLine(ctx, pattern, funcs, r, func(t T) error {
 		ts = append(ts, t)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
 	}
 	return ts, nil
 }
+
+// EachLine decodes each line of r and passes it to fnc. Scanning stops at the first error returned by fnc.
+func EachLine[T any](ctx context.Context, pattern string, funcs Funcs, r io.Reader, fnc func(t T) error, opts ...Option) error {
+	tpl, err := ParseTemplate("lines", pattern)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+	cfg := newConfig(opts...)
+	if cfg.workers > 1 {
+		return eachLineParallel(ctx, tpl, funcs, r, cfg, fnc)
+	}
+	return scanLines(ctx, r, cfg, func(ln line) error {
+		t, err := decodeLine[T](ctx, tpl, funcs, ln)
+		if err != nil {
+			return err
+		}
+		return fnc(t)
+	})
+}
+
+func eachLineParallel[T any](ctx context.Context, tpl *Template, funcs Funcs, r io.Reader, cfg config, fnc func(t T) error) error {
+	chunk := make([]line, 0, cfg.chunkSize)
+	ts := make([]T, cfg.chunkSize)
+	errs := make([]error, cfg.chunkSize)
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		idxs := make(chan int)
+		wg := sync.WaitGroup{}
+		for w := 0; w < cfg.workers; w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range idxs {
+					ts[i], errs[i] = decodeLine[T](ctx, tpl, funcs, chunk[i])
+				}
+			}()
+		}
+		for i := range chunk {
+			idxs <- i
+		}
+		close(idxs)
+		wg.Wait()
+
+		for i := range chunk {
+			if errs[i] != nil {
+				return errs[i]
+			}
+			if err := fnc(ts[i]); err != nil {
+				return err
+			}
+		}
+		chunk = chunk[:0]
+		return nil
+	}
+
+	err := scanLines(ctx, r, cfg, func(ln line) error {
+		chunk = append(chunk, ln)
+		if len(chunk) < cfg.chunkSize {
+			return nil
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
@@ -0,0 +1,260 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mazzegi/slices"
+	"github.com/pkg/errors"
+)
+
+func TestLines(t *testing.T) {
+	type point struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+	}
+	funcs := BuiltinFuncs()
+
+	tests := []struct {
+		in     string
+		opts   []Option
+		fail   bool
+		expect []point
+	}{
+		{
+			in:     "1,2\n3,4\n\n5,6\n",
+			expect: []point{{1, 2}, {3, 4}, {5, 6}},
+		},
+		{
+			in:     "1,2\r\n3,4\r\n",
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:     "1,2\r\n3,4",
+			opts:   []Option{Split(ScanCRLF)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:     "1,2\r3,4\r",
+			opts:   []Option{Split(ScanCR)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:     "1,2\x003,4\x00",
+			opts:   []Option{Split(ScanNUL)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:     "1,2\x1e3,4",
+			opts:   []Option{Split(ScanRecords)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:   "1,2\n" + strings.Repeat(" ", 100) + "3,4\n",
+			opts: []Option{MaxTokenSize(64)},
+			fail: true,
+		},
+		{
+			in:     "1,2\n" + strings.Repeat(" ", bufio.MaxScanTokenSize) + "3,4\n",
+			opts:   []Option{MaxTokenSize(2 * bufio.MaxScanTokenSize)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+		{
+			in:   "1,2\n" + strings.Repeat(" ", bufio.MaxScanTokenSize) + "3,4\n",
+			fail: true,
+		},
+		{
+			in:     "1,2\n" + strings.Repeat(" ", 100) + "3,4\n",
+			opts:   []Option{MaxTokenSize(-1)},
+			expect: []point{{1, 2}, {3, 4}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			ps, err := Lines[point]("{{x: int}},{{y: int}}", funcs, bytes.NewBufferString(test.in), test.opts...)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail, but got %v", ps)
+			}
+			if !reflect.DeepEqual(test.expect, ps) {
+				t.Fatalf("want %v, have %v", test.expect, ps)
+			}
+		})
+	}
+}
+
+func TestLinesContext(t *testing.T) {
+	type ctxKey struct{}
+	type item struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	funcs := BuiltinFuncs()
+	funcs.AddContext("lookup", func(ctx context.Context, s string) (any, error) {
+		m, _ := ctx.Value(ctxKey{}).(map[string]string)
+		v, ok := m[s]
+		if !ok {
+			return nil, errors.Errorf("no such key %q", s)
+		}
+		return v, nil
+	})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, map[string]string{"a": "alpha", "b": "beta"})
+	items, err := LinesContext[item](ctx, "{{name: string}} = {{value: lookup}}", funcs, bytes.NewBufferString("x = a\ny = b\n"))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []item{{"x", "alpha"}, {"y", "beta"}}, items)
+
+	_, err = Lines[item]("{{name: string}} = {{value: lookup}}", funcs, bytes.NewBufferString("x = a\n"))
+	noErrWhenErrExpected(t, err)
+}
+
+func TestLinesContextCancel(t *testing.T) {
+	funcs := BuiltinFuncs()
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		// no more writes, so the scanner blocks in Read afterwards
+		fmt.Fprint(pw, "1\n2\n")
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	var ns []int
+	err := EachLine(ctx, "{{n: int}}", funcs, pr, func(n struct{ N int }) error {
+		ns = append(ns, n.N)
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want %v, have %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Fatalf("cancellation took %s", d)
+	}
+	assertEqual(t, []int{1, 2}, ns)
+}
+
+func TestLinesParallel(t *testing.T) {
+	type item struct {
+		N   int    `json:"n"`
+		Sum int    `json:"sum"`
+		Tag string `json:"tag"`
+	}
+	funcs := BuiltinFuncs()
+	funcs.Add("sum", func(s string) (any, error) {
+		ns, err := slices.Convert(strings.Split(s, ","), slices.ParseInt)
+		if err != nil {
+			return nil, err
+		}
+		var sum int
+		for _, n := range ns {
+			sum += n
+		}
+		return sum, nil
+	})
+	pattern := "{{n: int}}: {{sum: sum}} ({{tag: string}})"
+
+	buf := &bytes.Buffer{}
+	for i := 0; i < 5000; i++ {
+		fmt.Fprintf(buf, "%d: %d,%d,%d (t%d)\n", i, i, i+1, i+2, i%7)
+	}
+	in := buf.String()
+
+	want, err := Lines[item](pattern, funcs, bytes.NewBufferString(in))
+	errWhenNoneExpected(t, err)
+	for _, opts := range [][]Option{
+		{Parallel(4)},
+		{Parallel(8), ChunkSize(100)},
+		{Parallel(3), ChunkSize(1)},
+	} {
+		have, err := Lines[item](pattern, funcs, bytes.NewBufferString(in), opts...)
+		errWhenNoneExpected(t, err)
+		assertEqual(t, want, have)
+	}
+
+	_, err = Lines[item](pattern, funcs, bytes.NewBufferString(in+"boom: 1,2 (t)\n"), Parallel(4), ChunkSize(64))
+	noErrWhenErrExpected(t, err)
+	if !strings.Contains(err.Error(), "line 5001") {
+		t.Fatalf("want error at line 5001, have %v", err)
+	}
+}
+
+func TestTemplateConcurrentEval(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{name: string}}: {{nums: []int}} and {{f: float}}")
+	errWhenNoneExpected(t, err)
+
+	wg := sync.WaitGroup{}
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				res, err := tpl.Eval(fmt.Sprintf("w%d: %d,%d and %d.5", w, i, w, i), funcs)
+				if err != nil {
+					t.Errorf("eval: %v", err)
+					return
+				}
+				var name string
+				var nums []int
+				var f float64
+				if err := res.Scan(&name, &nums, &f); err != nil {
+					t.Errorf("scan: %v", err)
+					return
+				}
+				if name != fmt.Sprintf("w%d", w) || !reflect.DeepEqual(nums, []int{i, w}) || f != float64(i)+0.5 {
+					t.Errorf("unexpected result %q, %v, %f", name, nums, f)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+}
+
+// endlessReader returns "1\n" forever and counts the reads
+type endlessReader struct {
+	reads int64
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	atomic.AddInt64(&r.reads, 1)
+	return copy(p, "1\n"), nil
+}
+
+func TestLinesStopReading(t *testing.T) {
+	funcs := BuiltinFuncs()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, c := range []context.Context{context.Background(), ctx} {
+		r := &endlessReader{}
+		errStop := errors.New("stop")
+		err := EachLine(c, "{{n: int}}", funcs, r, func(n struct{ N int }) error {
+			return errStop
+		})
+		if !errors.Is(err, errStop) {
+			t.Fatalf("want %v, have %v", errStop, err)
+		}
+		reads := atomic.LoadInt64(&r.reads)
+		time.Sleep(10 * time.Millisecond)
+		// a Read in progress may complete, but no further one is started
+		if atomic.LoadInt64(&r.reads) > reads+1 {
+			t.Fatalf("reader is still read after return")
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package scan
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Mode controls how a template is anchored in the input
+type Mode int
+
+const (
+	// ModeDefault behaves like Eval: the template is anchored at the start and trailing input is ignored
+	ModeDefault Mode = iota
+	// ModeFull requires the template to match the whole input
+	ModeFull
+	// ModePrefix matches the template at the start of the input. Match.End is the consumed length
+	ModePrefix
+	// ModeSearch finds the first position in the input, where the template matches
+	ModeSearch
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeDefault:
+		return "default"
+	case ModeFull:
+		return "full"
+	case ModePrefix:
+		return "prefix"
+	case ModeSearch:
+		return "search"
+	default:
+		return "unknown"
+	}
+}
+
+// Match is the result of a template evaluation together with its position. Start and End are byte offsets in the input.
+type Match struct {
+	Result *Result
+	Start  int
+	End    int
+}
+
+// EvalMode evaluates s according to mode and returns the result together with the matched range
+func (t *Template) EvalMode(s string, funcs Funcs, mode Mode) (*Match, error) {
+	return t.EvalModeContext(context.Background(), s, funcs, mode)
+}
+
+func (t *Template) EvalModeContext(ctx context.Context, s string, funcs Funcs, mode Mode) (*Match, error) {
+	in, offset := t.inputOffset(s)
+	var m *Match
+	var err error
+	switch mode {
+	case ModeDefault, ModePrefix:
+		m, err = t.matchAt(ctx, in, 0, funcs)
+	case ModeFull:
+		m, err = t.matchAt(ctx, in, 0, funcs)
+		if err == nil && m.End < len(in) {
+			err = errors.Errorf("unexpected trailing text %q", in[m.End:])
+		}
+	case ModeSearch:
+		m, err = t.search(ctx, in, 0, len(in), funcs, nil)
+	default:
+		err = errors.Errorf("invalid mode %d", mode)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.Start += offset
+	m.End += offset
+	return m, nil
+}
+
+// FindAll returns all non-overlapping matches of the template in s
+func (t *Template) FindAll(s string, funcs Funcs) ([]Match, error) {
+	return t.FindAllContext(context.Background(), s, funcs)
+}
+
+func (t *Template) FindAllContext(ctx context.Context, s string, funcs Funcs) ([]Match, error) {
+	if len(t.items) == 0 {
+		return nil, errors.Errorf("cannot find an empty template")
+	}
+	in, offset := t.inputOffset(s)
+	var ms []Match
+	for pos := 0; pos <= len(in); {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m, err := t.search(ctx, in, pos, len(in), funcs, nil)
+		if err != nil {
+			// a cancelled ctx makes the search fail as well, which must not pass as the end of the matches
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			break
+		}
+		pos = m.End
+		if m.End == m.Start {
+			// never get stuck on an empty match
+			if pos >= len(in) {
+				pos++
+			} else {
+				_, size := utf8.DecodeRuneInString(in[pos:])
+				pos += size
+			}
+		}
+		m.Start += offset
+		m.End += offset
+		ms = append(ms, *m)
+	}
+	return ms, nil
+}
+
+func (t *Template) matchAt(ctx context.Context, s string, start int, funcs Funcs) (*Match, error) {
+	res, begin, end, err := t.evalAt(ctx, s, start, funcs, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Match{
+		Result: res,
+		Start:  begin,
+		End:    end,
+	}, nil
+}
+
+// search tries to match the template at each candidate position in s between from and to (inclusive).
+// If incomplete is not nil, it is set to the first candidate, which failed because s ends, or -1.
+func (t *Template) search(ctx context.Context, s string, from, to int, funcs Funcs, incomplete *int) (*Match, error) {
+	if incomplete != nil {
+		*incomplete = -1
+	}
+	var first Item
+	if len(t.items) > 0 {
+		first = t.items[0]
+	}
+	var lastErr error = errors.Errorf("no match")
+	for pos := from; pos <= to; {
+		// jump to the next candidate
+		switch first := first.(type) {
+		case string:
+			idx := strings.Index(s[pos:], first)
+			if idx < 0 {
+				return nil, lastErr
+			}
+			pos += idx
+		case Whitespace:
+			idx := strings.IndexFunc(s[pos:], isWhite)
+			if idx < 0 {
+				return nil, lastErr
+			}
+			pos += idx
+		default:
+			if !t.opts.StrictLiterals {
+				// leading spaces are skipped anyway
+				for pos < len(s) && unicode.IsSpace(rune(s[pos])) {
+					pos++
+				}
+			}
+		}
+		if pos > to {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		m, err := t.matchAt(ctx, s, pos, funcs)
+		if err == nil {
+			return m, nil
+		}
+		lastErr = err
+		if incomplete != nil && *incomplete < 0 && isEndOfInput(err) {
+			*incomplete = pos
+		}
+		if pos >= len(s) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(s[pos:])
+		pos += size
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,163 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestEvalMode(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template string
+		in       string
+		mode     Mode
+		fail     bool
+		start    int
+		end      int
+		params   []ResultItem
+	}{
+		{
+			template: "x={{x: int}};",
+			in:       "x=1; and more",
+			mode:     ModeDefault,
+			start:    0,
+			end:      4,
+			params:   []ResultItem{{"x", 1}},
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "x=1; and more",
+			mode:     ModeFull,
+			fail:     true,
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "  x=1;  ",
+			mode:     ModeFull,
+			start:    2,
+			end:      6,
+			params:   []ResultItem{{"x", 1}},
+		},
+		{
+			template: "x={{x: int}};",
+			in:       " x=1; and more",
+			mode:     ModePrefix,
+			start:    1,
+			end:      5,
+			params:   []ResultItem{{"x", 1}},
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "first y=2; then x=1; and more",
+			mode:     ModeSearch,
+			start:    16,
+			end:      20,
+			params:   []ResultItem{{"x", 1}},
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "first x=a; then x=1; and more",
+			mode:     ModeSearch,
+			start:    16,
+			end:      20,
+			params:   []ResultItem{{"x", 1}},
+		},
+		{
+			template: "{{n: int}} apples",
+			in:       "I have 12 apples",
+			mode:     ModeSearch,
+			start:    7,
+			end:      16,
+			params:   []ResultItem{{"n", 12}},
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "first y=2; then z=1; and more",
+			mode:     ModeSearch,
+			fail:     true,
+		},
+		{
+			template: "x={{x: int}};",
+			in:       "x=1",
+			mode:     ModePrefix,
+			fail:     true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			m, err := tpl.EvalMode(test.in, funcs, test.mode)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail, but got %v", m)
+			}
+			assertEqual(t, test.start, m.Start)
+			assertEqual(t, test.end, m.End)
+			assertEqual(t, test.params, m.Result.Items)
+		})
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "<{{x: int}},{{y: int}}>")
+	errWhenNoneExpected(t, err)
+
+	in := "points <1,2> and <3,4>, not <5,a> but <6, 7>"
+	ms, err := tpl.FindAll(in, funcs)
+	errWhenNoneExpected(t, err)
+	var have [][]ResultItem
+	for _, m := range ms {
+		have = append(have, m.Result.Items)
+		if in[m.Start] != '<' || in[m.End-1] != '>' {
+			t.Fatalf("unexpected match position %d..%d: %q", m.Start, m.End, in[m.Start:m.End])
+		}
+	}
+	assertEqual(t, [][]ResultItem{
+		{{"x", 1}, {"y", 2}},
+		{{"x", 3}, {"y", 4}},
+		{{"x", 6}, {"y", 7}},
+	}, have)
+
+	ms, err = tpl.FindAll("nothing here", funcs)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 0, len(ms))
+
+	empty, err := ParseTemplate("test", "")
+	errWhenNoneExpected(t, err)
+	_, err = empty.FindAll(in, funcs)
+	noErrWhenErrExpected(t, err)
+}
+
+func TestFindAllContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var calls int
+	funcs := BuiltinFuncs()
+	funcs.AddContext("num", func(ctx context.Context, s string) (any, error) {
+		calls++
+		if calls == 2 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return strconv.Atoi(s)
+	})
+	tpl, err := ParseTemplate("test", "<{{x: num}}>")
+	errWhenNoneExpected(t, err)
+
+	ms, err := tpl.FindAllContext(ctx, "<1> <2> <3>", funcs)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want %v, have %v", context.Canceled, err)
+	}
+	assertEqual(t, 0, len(ms))
+}
@@ -1,82 +1,240 @@
 package scan
 
 import (
+	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 )
 
+const (
+	defaultLeftDelim  = "{{"
+	defaultRightDelim = "}}"
+	escapeRune        = '\\'
+)
+
+// ParseOptions control how template patterns are parsed. The zero value uses the defaults.
+type ParseOptions struct {
+	// LeftDelim and RightDelim enclose evalers. Default to "{{" and "}}"
+	LeftDelim  string
+	RightDelim string
+	// StrictLiterals preserves whitespace in literals and disables skipping of spaces in front of items.
+	// Input lines are not trimmed and captured values are passed to funcs as they are.
+	StrictLiterals bool
+}
+
+func (o ParseOptions) left() string {
+	if o.LeftDelim == "" {
+		return defaultLeftDelim
+	}
+	return o.LeftDelim
+}
+
+func (o ParseOptions) right() string {
+	if o.RightDelim == "" {
+		return defaultRightDelim
+	}
+	return o.RightDelim
+}
+
+// ParseError is returned for invalid patterns. Offset is the rune offset in the pattern, where the error was detected.
+type ParseError struct {
+	Offset int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("offset %d: %v", e.Offset, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseTemplate parses the pattern s with the default options.
+//
+// Literal delimiters are escaped with a backslash, e.g. \{{ or \}}. A literal backslash in front of a delimiter or
+// another backslash is written as \\.
+//
+// The skip evalers {{-}} and {{-: <func>}} and the wildcard {{*}} consume text without producing a result item.
+//
+// Whitespace is written with tokens: {{_}} matches one or more spaces or tabs, {{\s}}, {{\t}}, {{\r}} and {{\n}}
+// match a single space, tab, carriage return or newline. Whitespace between two evalers is treated like {{_}}.
+//
+// An empty capture is evaluated as the default of {{n: int=0}}, or yields nil for the nullable {{n: int?}}.
+// Funcs are chained with pipes like {{pct: trimsuffix("%") | float | div(100)}}. Each stage receives the value of the
+// previous one. Calls with args are created by the builtin factories.
+// Constraints like {{n: int|min=1}} are checked after evaluation (see Constraint).
 func ParseTemplate(name string, s string) (*Template, error) {
-	items, err := newItemsParser(s).parse()
+	return ParseTemplateWith(name, s, ParseOptions{})
+}
+
+func ParseTemplateWith(name string, s string, opts ParseOptions) (*Template, error) {
+	if opts.left() == opts.right() {
+		return nil, errors.Errorf("left and right delimiter must differ, got %q", opts.left())
+	}
+	p := newItemsParser(s, opts)
+	items, offsets, err := p.parse()
 	if err != nil {
 		return nil, err
 	}
 	//check items
 	lastWasEvaler := false
-	for _, item := range items {
-		switch item.(type) {
-		case Evaler:
-			if lastWasEvaler {
-				return nil, errors.Errorf("an evaler cannot immediately follow an evaler")
+	names := map[string]bool{}
+	for i, item := range items {
+		if ev, ok := item.(Evaler); ok && !strings.HasSuffix(ev.name, "[]") {
+			if names[ev.name] {
+				return nil, &ParseError{Offset: offsets[i], Err: errors.Errorf("duplicate evaler name %q", ev.name)}
 			}
-			lastWasEvaler = true
-		default:
+			names[ev.name] = true
+		}
+		if !isCapture(item) {
 			lastWasEvaler = false
+			continue
+		}
+		if lastWasEvaler {
+			return nil, &ParseError{Offset: offsets[i], Err: errors.Errorf("an evaler cannot immediately follow an evaler")}
 		}
+		lastWasEvaler = true
 	}
 
 	return &Template{
 		name:  name,
 		items: items,
+		opts:  opts,
 	}, nil
 }
 
-//
 type itemParseFunc func() (itemParseFunc, error)
 
+// gap marks whitespace-only text, which is dropped unless it separates two evalers
+type gap struct{}
+
+var whitespaceTokens = map[string]Item{
+	"_":  Whitespace{},
+	`\s`: " ",
+	`\t`: "\t",
+	`\r`: "\r",
+	`\n`: "\n",
+}
+
 type itemsParser struct {
-	rs    []rune
-	pos   int
-	items []Item
+	rs     []rune
+	pos    int
+	strict bool
+	items  []Item
+	// offsets holds the rune offset of each item in the pattern
+	offsets []int
+	left    []rune
+	right   []rune
 }
 
-func newItemsParser(s string) *itemsParser {
+func newItemsParser(s string, opts ParseOptions) *itemsParser {
 	p := &itemsParser{
-		rs:    []rune(s),
-		pos:   0,
-		items: []Item{},
+		rs:     []rune(s),
+		pos:    0,
+		strict: opts.StrictLiterals,
+		items:  []Item{},
+		left:   []rune(opts.left()),
+		right:  []rune(opts.right()),
 	}
 	return p
 }
 
-func (p *itemsParser) parse() ([]Item, error) {
+func (p *itemsParser) parse() ([]Item, []int, error) {
 	fnc := p.parseText
 	var err error
 	for fnc != nil {
 		fnc, err = fnc()
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
-	return p.items, nil
+
+	// resolve gaps and merge adjacent literals
+	items := []Item{}
+	var offsets []int
+	for i, item := range p.items {
+		switch item := item.(type) {
+		case gap:
+			if i == 0 || i == len(p.items)-1 {
+				continue
+			}
+			if isCapture(p.items[i-1]) && isCapture(p.items[i+1]) {
+				items = append(items, Whitespace{})
+				offsets = append(offsets, p.offsets[i])
+			}
+		case string:
+			if n := len(items); n > 0 {
+				if prev, ok := items[n-1].(string); ok {
+					items[n-1] = prev + item
+					continue
+				}
+			}
+			items = append(items, item)
+			offsets = append(offsets, p.offsets[i])
+		default:
+			items = append(items, item)
+			offsets = append(offsets, p.offsets[i])
+		}
+	}
+	return items, offsets, nil
+}
+
+func (p *itemsParser) hasPrefixAt(pos int, prefix []rune) bool {
+	if pos+len(prefix) > len(p.rs) {
+		return false
+	}
+	for i, r := range prefix {
+		if p.rs[pos+i] != r {
+			return false
+		}
+	}
+	return true
 }
 
 func (p *itemsParser) parseText() (itemParseFunc, error) {
 	var text string
+	start := p.pos
 	defer func() {
-		text = strings.TrimSpace(text)
+		if !p.strict {
+			if text != "" && strings.TrimSpace(text) == "" {
+				p.items = append(p.items, gap{})
+				p.offsets = append(p.offsets, start)
+				return
+			}
+			text = strings.TrimSpace(text)
+		}
 		if text == "" {
 			return
 		}
 		p.items = append(p.items, text)
+		p.offsets = append(p.offsets, start)
 	}()
 
 	for {
 		if p.pos >= len(p.rs) {
 			return nil, nil
 		}
-		if strings.HasPrefix(string(p.rs[p.pos:]), "{{") {
-			p.pos += 2
+		if p.rs[p.pos] == escapeRune {
+			switch {
+			case p.hasPrefixAt(p.pos+1, p.left):
+				text += string(p.left)
+				p.pos += 1 + len(p.left)
+				continue
+			case p.hasPrefixAt(p.pos+1, p.right):
+				text += string(p.right)
+				p.pos += 1 + len(p.right)
+				continue
+			case p.hasPrefixAt(p.pos+1, []rune{escapeRune}):
+				text += string(escapeRune)
+				p.pos += 2
+				continue
+			}
+		}
+		if p.hasPrefixAt(p.pos, p.left) {
+			p.pos += len(p.left)
 			return p.parseEvaler, nil
 		}
 		text += string(p.rs[p.pos])
@@ -84,19 +242,90 @@ func (p *itemsParser) parseText() (itemParseFunc, error) {
 	}
 }
 
+// parseEvaler looks for the closing delimiter outside of quoted args
 func (p *itemsParser) parseEvaler() (itemParseFunc, error) {
-	idx := strings.Index(string(p.rs[p.pos:]), "}}")
-	if idx < 0 {
-		return nil, errors.Errorf("no closing }} found")
+	start := p.pos - len(p.left)
+	var quote rune
+	for end := p.pos; end < len(p.rs); end++ {
+		r := p.rs[end]
+		switch {
+		case quote != 0:
+			if r == escapeRune && quote == '"' {
+				end++
+			} else if r == quote {
+				quote = 0
+			}
+			continue
+		case r == '"' || r == '`':
+			quote = r
+			continue
+		case !p.hasPrefixAt(end, p.right):
+			continue
+		}
+
+		sub := p.rs[p.pos:end]
+		if item, ok := whitespaceTokens[strings.TrimSpace(string(sub))]; ok {
+			p.items = append(p.items, item)
+			p.offsets = append(p.offsets, start)
+			p.pos = end + len(p.right)
+			return p.parseText, nil
+		}
+		if skip, ok := parseSkip(string(sub)); ok {
+			p.items = append(p.items, skip)
+			p.offsets = append(p.offsets, start)
+			p.pos = end + len(p.right)
+			return p.parseText, nil
+		}
+		ev, err := ParseEvaler(string(sub))
+		if err != nil {
+			return nil, &ParseError{Offset: start, Err: errors.Wrapf(err, "parse-evaler %q", string(sub))}
+		}
+		p.items = append(p.items, ev)
+		p.offsets = append(p.offsets, start)
+		p.pos = end + len(p.right)
+		return p.parseText, nil
 	}
-	sub := p.rs[p.pos : p.pos+idx]
+	return nil, &ParseError{Offset: start, Err: errors.Errorf("no closing %s found", string(p.right))}
+}
 
-	ev, err := ParseEvaler(string(sub))
-	if err != nil {
-		return nil, errors.Wrapf(err, "parse-evaler %q", sub)
+// parseSkip parses the skip evalers {{-}}, {{-: <func>}} and the wildcard {{*}}
+func parseSkip(s string) (Skip, bool) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return Skip{Wildcard: true}, true
+	}
+	name, funcName, _ := strings.Cut(s, ":")
+	if strings.TrimSpace(name) != "-" {
+		return Skip{}, false
 	}
-	p.items = append(p.items, ev)
+	return Skip{funcName: strings.TrimSpace(funcName)}, true
+}
+
+// escapeLiteral escapes delimiters and backslashes in s, so that it is parsed as literal text.
+// Unless literals are strict, surrounding whitespace is written as tokens.
+func escapeLiteral(s string, opts ParseOptions) string {
+	var lead, trail string
+	if !opts.StrictLiterals {
+		trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+		lead = s[:len(s)-len(trimmed)]
+		s = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+		trail = trimmed[len(s):]
+	}
+	s = strings.ReplaceAll(s, string(escapeRune), string(escapeRune)+string(escapeRune))
+	s = strings.ReplaceAll(s, opts.left(), string(escapeRune)+opts.left())
+	s = strings.ReplaceAll(s, opts.right(), string(escapeRune)+opts.right())
+	return whitespaceAsTokens(lead, opts) + s + whitespaceAsTokens(trail, opts)
+}
 
-	p.pos += idx + 2
-	return p.parseText, nil
+func whitespaceAsTokens(ws string, opts ParseOptions) string {
+	var sb strings.Builder
+	for _, r := range ws {
+		for tok, item := range whitespaceTokens {
+			if item == Item(string(r)) {
+				sb.WriteString(opts.left() + tok + opts.right())
+				break
+			}
+		}
+	}
+	return sb.String()
 }
@@ -0,0 +1,368 @@
+package scan
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestParseTemplateWith(t *testing.T) {
+	tests := []struct {
+		in     string
+		opts   ParseOptions
+		fail   bool
+		offset int
+		expect []Item
+	}{
+		{
+			in: `tpl \{{.Name\}} is {{name: string}}`,
+			expect: []Item{
+				"tpl {{.Name}} is",
+				Evaler{raw: "name: string", name: "name", funcName: "string"},
+			},
+		},
+		{
+			in: `json {"a": {"b": {{b: int}}}}`,
+			expect: []Item{
+				`json {"a": {"b":`,
+				Evaler{raw: "b: int", name: "b", funcName: "int"},
+				"}}",
+			},
+		},
+		{
+			in: `{{parts: split("}}")}} and a \\ backslash`,
+			expect: []Item{
+				Evaler{raw: `parts: split("}}")`, name: "parts", funcName: `split("}}")`},
+				`and a \ backslash`,
+			},
+		},
+		{
+			in:   `tpl {{.Name}} is <<name: string>>`,
+			opts: ParseOptions{LeftDelim: "<<", RightDelim: ">>"},
+			expect: []Item{
+				"tpl {{.Name}} is",
+				Evaler{raw: "name: string", name: "name", funcName: "string"},
+			},
+		},
+		{
+			in:   `\<<a\>> <<a: int>>`,
+			opts: ParseOptions{LeftDelim: "<<", RightDelim: ">>"},
+			expect: []Item{
+				"<<a>>",
+				Evaler{raw: "a: int", name: "a", funcName: "int"},
+			},
+		},
+		{
+			in:   "<<a: int>>",
+			opts: ParseOptions{LeftDelim: "<<", RightDelim: "<<"},
+			fail: true,
+		},
+		{
+			in:     "äöü {{a: int}} and {{b: int",
+			fail:   true,
+			offset: 19,
+		},
+		{
+			in:     "äöü {{a: int}}{{b: int}}",
+			fail:   true,
+			offset: 14,
+		},
+		{
+			in:     "abc {{a int}}",
+			fail:   true,
+			offset: 4,
+		},
+		{
+			in:     `{{a: split("}}}}`,
+			fail:   true,
+			offset: 0,
+		},
+		{
+			in:     "{{a: int}} and {{a: float}}",
+			fail:   true,
+			offset: 15,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplateWith("test", test.in, test.opts)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				var perr *ParseError
+				if errors.As(err, &perr) {
+					assertEqual(t, test.offset, perr.Offset)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail")
+			}
+			assertEqual(t, test.expect, tpl.items)
+
+			tpl2, err := ParseTemplateWith("test", tpl.String(), test.opts)
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.items, tpl2.items)
+		})
+	}
+}
+
+func TestWhitespace(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template string
+		opts     ParseOptions
+		in       string
+		fail     bool
+		items    []Item
+		params   []ResultItem
+	}{
+		{
+			template: "{{user: string}}{{_}}{{pid: int}}{{_}}{{cmd: string}}",
+			in:       "root   1 \t /sbin/init splash",
+			items:    []Item{Evaler{raw: "user: string", name: "user", funcName: "string"}, Whitespace{}, Evaler{raw: "pid: int", name: "pid", funcName: "int"}, Whitespace{}, Evaler{raw: "cmd: string", name: "cmd", funcName: "string"}},
+			params:   []ResultItem{{"user", "root"}, {"pid", 1}, {"cmd", "/sbin/init splash"}},
+		},
+		{
+			template: "{{user: string}} {{pid: int}}",
+			in:       "root\t1",
+			items:    []Item{Evaler{raw: "user: string", name: "user", funcName: "string"}, Whitespace{}, Evaler{raw: "pid: int", name: "pid", funcName: "int"}},
+			params:   []ResultItem{{"user", "root"}, {"pid", 1}},
+		},
+		{
+			template: "{{a: int}}{{\\t}}{{b: int}}",
+			in:       "1 2\t3",
+			fail:     true,
+			items:    []Item{Evaler{raw: "a: int", name: "a", funcName: "int"}, "\t", Evaler{raw: "b: int", name: "b", funcName: "int"}},
+		},
+		{
+			template: "{{a: string}}{{\\t}}{{b: int}}",
+			in:       "1 2\t3",
+			items:    []Item{Evaler{raw: "a: string", name: "a", funcName: "string"}, "\t", Evaler{raw: "b: int", name: "b", funcName: "int"}},
+			params:   []ResultItem{{"a", "1 2"}, {"b", 3}},
+		},
+		{
+			template: "a:{{\\s}}{{a: int}}",
+			in:       "a:  1",
+			items:    []Item{"a: ", Evaler{raw: "a: int", name: "a", funcName: "int"}},
+			params:   []ResultItem{{"a", 1}},
+		},
+		{
+			template: "{{a: string}}  {{b: string}} ",
+			opts:     ParseOptions{StrictLiterals: true},
+			in:       "x y  z ",
+			items:    []Item{Evaler{raw: "a: string", name: "a", funcName: "string"}, "  ", Evaler{raw: "b: string", name: "b", funcName: "string"}, " "},
+			params:   []ResultItem{{"a", "x y"}, {"b", "z"}},
+		},
+		{
+			template: "{{a: string}}  {{b: string}} ",
+			opts:     ParseOptions{StrictLiterals: true},
+			in:       "x y  z",
+			fail:     true,
+			items:    []Item{Evaler{raw: "a: string", name: "a", funcName: "string"}, "  ", Evaler{raw: "b: string", name: "b", funcName: "string"}, " "},
+		},
+		{
+			template: " a {{b: string}}",
+			opts:     ParseOptions{StrictLiterals: true},
+			in:       " a  b",
+			items:    []Item{" a ", Evaler{raw: "b: string", name: "b", funcName: "string"}},
+			params:   []ResultItem{{"b", " b"}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplateWith("test", test.template, test.opts)
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.items, tpl.items)
+
+			tpl2, err := ParseTemplateWith("test", tpl.String(), test.opts)
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.items, tpl2.items)
+
+			res, err := tpl.Eval(test.in, funcs)
+			if err != nil {
+				if !test.fail {
+					t.Fatalf("expect NOT to fail, but got %v", err)
+				}
+				return
+			}
+			if test.fail {
+				t.Fatalf("expect to fail")
+			}
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
+
+func TestSkip(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template string
+		in       string
+		items    []Item
+		fail     bool
+		params   []ResultItem
+	}{
+		{
+			template: "{{-}} [{{level: string}}] {{msg: string}}",
+			in:       "2024-01-01 10:00:00 [info] started",
+			items:    []Item{Skip{}, "[", Evaler{raw: "level: string", name: "level", funcName: "string"}, "]", Evaler{raw: "msg: string", name: "msg", funcName: "string"}},
+			params:   []ResultItem{{"level", "info"}, {"msg", "started"}},
+		},
+		{
+			template: "{{-: time}} {{n: int}}",
+			in:       "yesterday 42",
+			items:    []Item{Skip{funcName: "time"}, Whitespace{}, Evaler{raw: "n: int", name: "n", funcName: "int"}},
+			params:   []ResultItem{{"n", 42}},
+		},
+		{
+			template: "a={{a: int}},{{*}}c={{c: int}} {{*}}",
+			in:       "a=1, b=2, c=3",
+			items:    []Item{"a=", Evaler{raw: "a: int", name: "a", funcName: "int"}, ",", Skip{Wildcard: true}, "c=", Evaler{raw: "c: int", name: "c", funcName: "int"}, Whitespace{}, Skip{Wildcard: true}},
+			fail:     true,
+		},
+		{
+			template: "a={{a: int}},{{*}}c={{c: int}};{{*}}",
+			in:       "a=1, c=3;",
+			items:    []Item{"a=", Evaler{raw: "a: int", name: "a", funcName: "int"}, ",", Skip{Wildcard: true}, "c=", Evaler{raw: "c: int", name: "c", funcName: "int"}, ";", Skip{Wildcard: true}},
+			params:   []ResultItem{{"a", 1}, {"c", 3}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.items, tpl.Items())
+			tpl2, err := ParseTemplate("test", tpl.String())
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.String(), tpl2.String())
+
+			res, err := tpl.Eval(test.in, funcs)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+
+	tpl, err := ParseTemplate("test", "{{-}} [{{level: string}}] {{msg: string}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval("2024-01-01 [warn] disk full", funcs)
+	errWhenNoneExpected(t, err)
+	var level, msg string
+	errWhenNoneExpected(t, res.Scan(&level, &msg))
+	assertEqual(t, "warn", level)
+	assertEqual(t, "disk full", msg)
+
+	_, err = ParseTemplate("test", "{{a: int}}{{-}}")
+	noErrWhenErrExpected(t, err)
+}
+
+func TestDefaults(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template  string
+		in        string
+		parseFail bool
+		evalFail  bool
+		params    []ResultItem
+	}{
+		{
+			template: "{{name: string}},{{count: int=0}},{{ratio: float=0.5}}",
+			in:       "a,,",
+			params:   []ResultItem{{"name", "a"}, {"count", 0}, {"ratio", 0.5}},
+		},
+		{
+			template: "{{name: string}},{{count: int=0}},{{ratio: float=0.5}}",
+			in:       "a, 3 , 1.5",
+			params:   []ResultItem{{"name", "a"}, {"count", 3}, {"ratio", 1.5}},
+		},
+		{
+			template: "{{name: string}},{{count: int?}},{{ratio: float?}}",
+			in:       "a,,",
+			params:   []ResultItem{{"name", "a"}, {"count", nil}, {"ratio", nil}},
+		},
+		{
+			template: `{{name: string="n/a"}},{{count: int?|min=1}}`,
+			in:       ",",
+			params:   []ResultItem{{"name", "n/a"}, {"count", nil}},
+		},
+		{
+			template: `{{name: string}},{{count: int=0|min=1}}`,
+			in:       "a,",
+			evalFail: true,
+		},
+		{
+			template: "{{name: string}},{{count: int}}",
+			in:       "a,",
+			evalFail: true,
+		},
+		{
+			template: `{{mail: string="x@y"}},{{count: int}}`,
+			in:       ",1",
+			params:   []ResultItem{{"mail", "x@y"}, {"count", 1}},
+		},
+		{
+			template:  "{{count: int?=0}}",
+			parseFail: true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			if test.parseFail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			tpl2, err := ParseTemplate("test", tpl.String())
+			errWhenNoneExpected(t, err)
+			assertEqual(t, tpl.String(), tpl2.String())
+			for i, ev := range tpl.Evalers() {
+				ev2 := tpl2.Evalers()[i]
+				def, hasDef := ev.Default()
+				def2, hasDef2 := ev2.Default()
+				assertEqual(t, def, def2)
+				assertEqual(t, hasDef, hasDef2)
+				assertEqual(t, ev.Column(), ev2.Column())
+			}
+
+			res, err := tpl.Eval(test.in, funcs)
+			if test.evalFail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
+
+func TestDecodeNullable(t *testing.T) {
+	type row struct {
+		Name  string   `json:"name"`
+		Count *int     `json:"count"`
+		Ratio *float64 `json:"ratio"`
+	}
+	tpl, err := ParseTemplate("test", "{{name: string}},{{count: int?}},{{ratio: float?}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval("a,,0.5", BuiltinFuncs())
+	errWhenNoneExpected(t, err)
+	var r row
+	errWhenNoneExpected(t, res.Decode(&r))
+	if r.Count != nil || r.Ratio == nil || *r.Ratio != 0.5 {
+		t.Fatalf("unexpected row %+v", r)
+	}
+
+	var name string
+	n := 42
+	np := &n
+	errWhenNoneExpected(t, res.Scan(&name, &np))
+	if np != nil {
+		t.Fatalf("expect nil pointer, got %v", *np)
+	}
+}
@@ -0,0 +1,158 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Capturer is implemented by funcs, which determine the extent of their token themselves, e.g. a quoted string
+// containing the next literal. Capture returns the length of the token at the start of s. If ok is false,
+// the text up to the next item is captured as usual.
+type Capturer interface {
+	Func
+	Capture(s string) (n int, ok bool)
+}
+
+// CaptureFunc is a Capturer made of a capture and an eval func
+type CaptureFunc struct {
+	CaptureFnc func(s string) (int, bool)
+	EvalFnc    EvalFunc
+}
+
+func (f CaptureFunc) Capture(s string) (int, bool) {
+	return f.CaptureFnc(s)
+}
+
+func (f CaptureFunc) EvalContext(ctx context.Context, s string) (any, error) {
+	return f.EvalFnc.EvalContext(ctx, s)
+}
+
+// quotedPrefix returns the length of the token at the start of s, which is enclosed in double or single quotes.
+// Inside, a backslash escapes the next character and a doubled quote stands for the quote itself.
+func quotedPrefix(s string) (int, bool) {
+	if s == "" || (s[0] != '"' && s[0] != '\'') {
+		return 0, false
+	}
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case q:
+			if i+1 < len(s) && s[i+1] == q {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
+
+// unquoteToken removes the quotes of a token found by quotedPrefix and resolves escapes
+func unquoteToken(tok string) string {
+	q := tok[0]
+	inner := tok[1 : len(tok)-1]
+	var sb strings.Builder
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case inner[i] == '\\' && i+1 < len(inner):
+			i++
+		case inner[i] == q && i+1 < len(inner) && inner[i+1] == q:
+			i++
+		}
+		sb.WriteByte(inner[i])
+	}
+	return sb.String()
+}
+
+// unquoteQuoted unquotes s, if it is quoted, and returns bare strings as they are
+func unquoteQuoted(s string) (string, error) {
+	n, ok := quotedPrefix(s)
+	switch {
+	case ok && n == len(s):
+		return unquoteToken(s), nil
+	case ok:
+		return "", errors.Errorf("unexpected text %q after quoted string", s[n:])
+	case s != "" && (s[0] == '"' || s[0] == '\''):
+		return "", errors.Errorf("unterminated quoted string %s", s)
+	default:
+		return s, nil
+	}
+}
+
+// quotedListPrefix returns the length of a comma-separated list of quoted tokens at the start of s
+func quotedListPrefix(s string) (int, bool) {
+	pos := 0
+	for {
+		n, ok := quotedPrefix(s[pos:])
+		if !ok {
+			return 0, false
+		}
+		pos += n
+		rest := strings.TrimLeft(s[pos:], " \t")
+		if !strings.HasPrefix(rest, ",") {
+			return pos, true
+		}
+		next := strings.TrimLeft(rest[1:], " \t")
+		if !strings.HasPrefix(next, `"`) && !strings.HasPrefix(next, "'") {
+			// a bare element. let the list be captured up to the next item
+			return 0, false
+		}
+		pos = len(s) - len(next)
+	}
+}
+
+// splitQuoted splits s at commas outside of quoted elements and unquotes the elements
+func splitQuoted(s string) ([]string, error) {
+	var elems []string
+	rest := s
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		var elem string
+		if n, ok := quotedPrefix(rest); ok {
+			elem = unquoteToken(rest[:n])
+			rest = strings.TrimLeft(rest[n:], " \t")
+			if rest != "" && rest[0] != ',' {
+				return nil, errors.Errorf("unexpected text %q after quoted element", rest)
+			}
+		} else {
+			if rest != "" && (rest[0] == '"' || rest[0] == '\'') {
+				return nil, errors.Errorf("unterminated quoted element %s", rest)
+			}
+			idx := strings.Index(rest, ",")
+			if idx < 0 {
+				idx = len(rest)
+			}
+			elem = strings.TrimSpace(rest[:idx])
+			rest = rest[idx:]
+		}
+		elems = append(elems, elem)
+		if rest == "" {
+			return elems, nil
+		}
+		rest = rest[1:]
+	}
+}
+
+func goQuotedPrefix(s string) (int, bool) {
+	qs, err := strconv.QuotedPrefix(s)
+	if err != nil {
+		return 0, false
+	}
+	return len(qs), true
+}
+
+// jsonPrefix returns the length of the JSON value at the start of s
+func jsonPrefix(s string) (int, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return 0, false
+	}
+	return int(dec.InputOffset()), true
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestQuoted(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tests := []struct {
+		template string
+		in       string
+		fail     bool
+		params   []ResultItem
+	}{
+		{
+			template: "{{a: quoted}}, {{b: quoted}}, {{c: int}}",
+			in:       `"a, b", 'x:y', 3`,
+			params:   []ResultItem{{"a", "a, b"}, {"b", "x:y"}, {"c", 3}},
+		},
+		{
+			template: "{{a: quoted}}, {{b: quoted}}",
+			in:       `bare, "say ""hi"", \"bye\""`,
+			params:   []ResultItem{{"a", "bare"}, {"b", `say "hi", "bye"`}},
+		},
+		{
+			template: "{{a: quoted}}: {{b: string}}",
+			in:       `'it''s: here': there`,
+			params:   []ResultItem{{"a", "it's: here"}, {"b", "there"}},
+		},
+		{
+			template: "{{a: quoted}}, {{b: int}}",
+			in:       `"a, b" x, 3`,
+			fail:     true,
+		},
+		{
+			template: "{{a: quoted}}",
+			in:       `"unterminated`,
+			fail:     true,
+		},
+		{
+			template: "{{a: goquoted}} = {{b: goquoted}}",
+			in:       "\"a = \\u00e4\\n\" = `raw = \\n`",
+			params:   []ResultItem{{"a", "a = ä\n"}, {"b", `raw = \n`}},
+		},
+		{
+			template: "{{a: goquoted}}",
+			in:       "'ab'",
+			fail:     true,
+		},
+		{
+			template: "[{{tags: []quoted}}] {{n: int}}",
+			in:       `["a, b", 'c]', "d"] 4`,
+			params:   []ResultItem{{"tags", []string{"a, b", "c]", "d"}}, {"n", 4}},
+		},
+		{
+			template: "[{{tags: []quoted}}] {{n: int}}",
+			in:       `["a, b", c, d] 4`,
+			params:   []ResultItem{{"tags", []string{"a, b", "c", "d"}}, {"n", 4}},
+		},
+		{
+			template: "{{a: quoted | upper}}, {{b: int}}",
+			in:       `"a, b", 3`,
+			params:   []ResultItem{{"a", "A, B"}, {"b", 3}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, err := ParseTemplate("test", test.template)
+			errWhenNoneExpected(t, err)
+			res, err := tpl.Eval(test.in, funcs)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type user struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	type entry struct {
+		Day   string          `json:"day"`
+		Level string          `json:"level"`
+		User  user            `json:"user"`
+		Raw   json.RawMessage `json:"raw"`
+		N     int             `json:"n"`
+	}
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{day: string}} {{level: string}} {{user: json}} {{raw: json}} }}{{n: int}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval(`2024-01-01 INFO {"name": "bob }}", "tags": ["a", "b"]} [1, {"x": "}}"}] }}42`, funcs)
+	errWhenNoneExpected(t, err)
+	v, _ := res.Get("user")
+	assertEqual(t, json.RawMessage(`{"name": "bob }}", "tags": ["a", "b"]}`), v)
+
+	var e entry
+	errWhenNoneExpected(t, res.Decode(&e))
+	assertEqual(t, entry{
+		Day:   "2024-01-01",
+		Level: "INFO",
+		User:  user{Name: "bob }}", Tags: []string{"a", "b"}},
+		Raw:   json.RawMessage(`[1, {"x": "}}"}]`),
+		N:     42,
+	}, e)
+
+	_, err = tpl.Eval(`2024-01-01 INFO {"name": "bob" [1] }}42`, funcs)
+	noErrWhenErrExpected(t, err)
+	res, err = tpl.Eval(`2024-01-01 INFO "bob" 1 }}42`, funcs)
+	errWhenNoneExpected(t, err)
+	noErrWhenErrExpected(t, res.Decode(&e))
+}
+
+func TestJSONScalars(t *testing.T) {
+	type scalars struct {
+		S   string
+		N   int
+		B   bool
+		P   *float64
+		Raw json.RawMessage
+	}
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{s: json}} {{n: json}} {{b: json}} {{p: json}} {{raw: json}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval(`"bob \"b\"" 42 true 1.5 "x"`, funcs)
+	errWhenNoneExpected(t, err)
+	var sc scalars
+	errWhenNoneExpected(t, res.Decode(&sc))
+	assertEqual(t, scalars{S: `bob "b"`, N: 42, B: true, P: ptrVal(1.5), Raw: json.RawMessage(`"x"`)}, sc)
+
+	res, err = tpl.Eval(`"bob" 4.5 true 1 "x"`, funcs)
+	errWhenNoneExpected(t, err)
+	noErrWhenErrExpected(t, res.Decode(&sc))
+}
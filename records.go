@@ -0,0 +1,148 @@
+package scan
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// column binds an evaler to the index of a record field
+type column struct {
+	ev  Evaler
+	idx int
+}
+
+// bindColumns resolves the columns of the evalers of tpl. An evaler with a numeric column like {{amount: float@3}} is
+// bound to that (zero-based) index, one with a name like {{amount: float@Amount}} to the header column with that name.
+// Header names are compared case-insensitively.
+// Without a column, evalers are bound to the header column with their name or, without a header, by position,
+// where skip items like {{-}} count as well. Literals are ignored.
+func bindColumns(tpl *Template, header []string) ([]column, error) {
+	var cols []column
+	var pos int
+	for _, item := range tpl.items {
+		switch item := item.(type) {
+		case Skip:
+			pos++
+		case Evaler:
+			name := item.column
+			if name == "" {
+				name = item.name
+				if header == nil {
+					cols = append(cols, column{ev: item, idx: pos})
+					pos++
+					continue
+				}
+			}
+			pos++
+			if idx, err := strconv.Atoi(name); err == nil {
+				if idx < 0 {
+					return nil, errors.Errorf("evaler %q: invalid column %d", item.name, idx)
+				}
+				cols = append(cols, column{ev: item, idx: idx})
+				continue
+			}
+			if header == nil {
+				return nil, errors.Errorf("evaler %q: column %q requires a header", item.name, name)
+			}
+			idx := -1
+			for i, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), name) {
+					idx = i
+					break
+				}
+			}
+			if idx < 0 {
+				return nil, errors.Errorf("evaler %q: no column %q in header", item.name, name)
+			}
+			cols = append(cols, column{ev: item, idx: idx})
+		}
+	}
+	return cols, nil
+}
+
+// Records reads CSV records from r and decodes each into T. The evalers of pattern are bound to columns (see Comma and Header).
+func Records[T any](pattern string, funcs Funcs, r io.Reader, opts ...Option) ([]T, error) {
+	return RecordsContext[T](context.Background(), pattern, funcs, r, opts...)
+}
+
+// RecordsContext is like Records, but stops when ctx is done and passes ctx to context-aware funcs
+func RecordsContext[T any](ctx context.Context, pattern string, funcs Funcs, r io.Reader, opts ...Option) ([]T, error) {
+	var ts []T
+	err := EachRecord(ctx, pattern, funcs, r, func(t T) error {
+		ts = append(ts, t)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// EachRecord decodes each record of r and passes it to fnc. Reading stops at the first error returned by fnc.
+func EachRecord[T any](ctx context.Context, pattern string, funcs Funcs, r io.Reader, fnc func(t T) error, opts ...Option) error {
+	tpl, err := ParseTemplate("records", pattern)
+	if err != nil {
+		return errors.Wrap(err, "parse template")
+	}
+	cfg := newConfig(opts...)
+	cr := csv.NewReader(r)
+	cr.Comma = cfg.comma
+	cr.ReuseRecord = true
+
+	var header []string
+	var recordNo int
+	if cfg.header {
+		rec, err := cr.Read()
+		if err != nil {
+			return errors.Wrap(err, "read header")
+		}
+		recordNo++
+		header = append([]string{}, rec...)
+	}
+	cols, err := bindColumns(tpl, header)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return errors.Wrapf(err, "cancelled at record %d", recordNo)
+		}
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		recordNo++
+		if err != nil {
+			return errors.Wrapf(err, "read record %d", recordNo)
+		}
+		res := &Result{}
+		for _, col := range cols {
+			if col.idx >= len(rec) {
+				return errors.Errorf("record %d: evaler %q: no column %d", recordNo, col.ev.name, col.idx)
+			}
+			field := rec[col.idx]
+			if !tpl.opts.StrictLiterals {
+				field = strings.TrimSpace(field)
+			}
+			v, err := col.ev.EvalContext(ctx, field, funcs)
+			if err != nil {
+				return errors.Wrapf(err, "record %d: eval %q", recordNo, field)
+			}
+			res.Items = append(res.Items, ResultItem{col.ev.name, v})
+		}
+		var t T
+		err = res.Decode(&t)
+		if err != nil {
+			return errors.Wrapf(err, "decode record %d", recordNo)
+		}
+		if err := fnc(t); err != nil {
+			return err
+		}
+	}
+}
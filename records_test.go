@@ -0,0 +1,120 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+type booking struct {
+	Date    time.Time `json:"date"`
+	Account string    `json:"account"`
+	Amount  float64   `json:"amount"`
+	Note    *string   `json:"note"`
+}
+
+func TestRecords(t *testing.T) {
+	funcs := BuiltinFuncs()
+	funcs["date"] = func(s string) (any, error) {
+		return time.Parse("2006-01-02", s)
+	}
+	day := func(d int) time.Time {
+		return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC)
+	}
+	note := func(s string) *string {
+		return &s
+	}
+
+	tests := []struct {
+		pattern string
+		in      string
+		opts    []Option
+		fail    bool
+		exp     []booking
+	}{
+		{
+			pattern: "{{date: date}}, {{account: string}}, {{amount: float}}, {{note: string?}}",
+			in:      "2024-01-01,\"Doe, John\",12.5,\n2024-01-02,Acme,-3,\"said \"\"hi\"\"\"\n",
+			exp: []booking{
+				{day(1), "Doe, John", 12.5, nil},
+				{day(2), "Acme", -3, note(`said "hi"`)},
+			},
+		},
+		{
+			pattern: "{{amount: float@3}} {{date: date@0}}",
+			in:      "2024-01-01;x;y;12.5\n2024-01-02;x;y;1\n",
+			opts:    []Option{Comma(';')},
+			exp: []booking{
+				{Date: day(1), Amount: 12.5},
+				{Date: day(2), Amount: 1},
+			},
+		},
+		{
+			pattern: "{{-}} {{date: date}} {{-}} {{amount: float}}",
+			in:      "x\t2024-01-01\ty\t12.5\n",
+			opts:    []Option{Comma('\t')},
+			exp: []booking{
+				{Date: day(1), Amount: 12.5},
+			},
+		},
+		{
+			pattern: "{{amount: float}} {{account: string@Name}} {{date: date}}",
+			in:      "Date, Name,Amount\n2024-01-01,Acme,12.5\n",
+			opts:    []Option{Header()},
+			exp: []booking{
+				{Date: day(1), Account: "Acme", Amount: 12.5},
+			},
+		},
+		{
+			pattern: "{{amount: float}} {{account: string@Account}}",
+			in:      "Date,Name,Amount\n2024-01-01,Acme,12.5\n",
+			opts:    []Option{Header()},
+			fail:    true,
+		},
+		{
+			pattern: "{{amount: float}} {{account: string@Name}}",
+			in:      "2024-01-01,Acme,12.5\n",
+			fail:    true,
+		},
+		{
+			pattern: "{{amount: float@5}}",
+			in:      "2024-01-01,Acme,12.5\n",
+			fail:    true,
+		},
+		{
+			pattern: "{{date: date}}, {{account: string}}, {{amount: float}}",
+			in:      "2024-01-01,Acme,much\n",
+			fail:    true,
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			bs, err := Records[booking](test.pattern, funcs, bytes.NewBufferString(test.in), test.opts...)
+			if test.fail {
+				noErrWhenErrExpected(t, err)
+				return
+			}
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.exp, bs)
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := RecordsContext[booking](ctx, "{{account: string}}", funcs, bytes.NewBufferString("a\nb\n"))
+	noErrWhenErrExpected(t, err)
+}
+
+func TestCSVFunc(t *testing.T) {
+	tpl, err := ParseTemplate("test", "tags: {{tags: []csv}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval(`tags: a, "b, c",, d`, BuiltinFuncs())
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"tags", []string{"a", "b, c", "", "d"}}}, res.Items)
+
+	tpl, err = ParseTemplate("test", "{{n: int=0@2}}")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, "{{n: int=0@2}}", tpl.String())
+}
@@ -1,7 +1,10 @@
 package scan
 
 import (
-	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -17,26 +20,170 @@ type Result struct {
 
 func (r *Result) Scan(args ...any) error {
 	if len(args) > len(r.Items) {
-		return errors.Errorf("to many args. want %d at most, got %d", len(r.Items), len(args))
+		return errors.Errorf("too many args. want %d at most, got %d", len(r.Items), len(args))
 	}
 	for i, arg := range args {
 		err := copyAny(r.Items[i].Value, arg)
 		if err != nil {
-			return err
+			return errors.Wrapf(err, "scan item %q", r.Items[i].Name)
 		}
 	}
 	return nil
 }
 
+// ScanOption configures ScanNamed and ScanInto
+type ScanOption func(c *scanConfig)
+
+type scanConfig struct {
+	requireAll bool
+}
+
+// RequireAll makes ScanNamed and ScanInto fail, if not every result item is scanned
+func RequireAll() ScanOption {
+	return func(c *scanConfig) {
+		c.requireAll = true
+	}
+}
+
+// ScanNamed copies the values of the result items into the pointers of m, keyed by evaler name
+func (r *Result) ScanNamed(m map[string]any, opts ...ScanOption) error {
+	var cfg scanConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		v, ok := r.Get(name)
+		if !ok {
+			return errors.Errorf("no result item %q", name)
+		}
+		err := copyAny(v, m[name])
+		if err != nil {
+			return errors.Wrapf(err, "scan item %q", name)
+		}
+	}
+	if cfg.requireAll {
+		var missing []string
+		for _, item := range r.Items {
+			if _, ok := m[item.Name]; !ok {
+				missing = append(missing, item.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return errors.Errorf("result items not scanned: %s", strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
+
+// ScanInto is like ScanNamed with name and pointer pairs, e.g. ScanInto("x", &x, "y", &y). ScanOptions may be passed among the args.
+func (r *Result) ScanInto(args ...any) error {
+	m := map[string]any{}
+	var opts []ScanOption
+	for i := 0; i < len(args); i++ {
+		if opt, ok := args[i].(ScanOption); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		name, ok := args[i].(string)
+		if !ok {
+			return errors.Errorf("arg %d: want name, got %T", i, args[i])
+		}
+		if i+1 >= len(args) {
+			return errors.Errorf("arg %d: missing target for %q", i, name)
+		}
+		if _, ok := m[name]; ok {
+			return errors.Errorf("arg %d: %q given twice", i, name)
+		}
+		m[name] = args[i+1]
+		i++
+	}
+	return r.ScanNamed(m, opts...)
+}
+
+// Decode sets the result items in v, which is a pointer to a struct or a map. Dotted evaler names like "point.x"
+// decode into nested structs and maps, names ending with [] like "tags[]" append to slices.
+// A map[string]string, e.g. from logfmt, is decoded key by key into typed fields. With the name "." its keys are
+// decoded into v itself.
 func (r *Result) Decode(v any) error {
-	msa := map[string]any{}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.Errorf("cannot decode into non-pointer or nil %T", v)
+	}
 	for _, item := range r.Items {
-		msa[item.Name] = item.Value
+		path, err := parsePath(item.Name)
+		if err != nil {
+			return err
+		}
+		err = decodePath(rv.Elem(), path, item.Value)
+		if err != nil {
+			return errors.Wrapf(err, "decode %q", item.Name)
+		}
+	}
+	return nil
+}
+
+// Get returns the value of the item with the given name
+func (r *Result) Get(name string) (any, bool) {
+	for _, item := range r.Items {
+		if item.Name == name {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
+
+func (r *Result) Has(name string) bool {
+	_, ok := r.Get(name)
+	return ok
+}
+
+// Names returns the names of the items in order
+func (r *Result) Names() []string {
+	names := make([]string, len(r.Items))
+	for i, item := range r.Items {
+		names[i] = item.Name
+	}
+	return names
+}
+
+func (r *Result) Int(name string) (int, error) {
+	return Value[int](r, name)
+}
+
+func (r *Result) Float(name string) (float64, error) {
+	return Value[float64](r, name)
+}
+
+func (r *Result) String(name string) (string, error) {
+	return Value[string](r, name)
+}
+
+func (r *Result) Bool(name string) (bool, error) {
+	return Value[bool](r, name)
+}
+
+func (r *Result) Time(name string) (time.Time, error) {
+	return Value[time.Time](r, name)
+}
+
+// Value returns the value of the item with the given name, if it is of type T
+func Value[T any](r *Result, name string) (T, error) {
+	var t T
+	v, ok := r.Get(name)
+	if !ok {
+		return t, errors.Errorf("no result item %q", name)
+	}
+	if v == nil {
+		return t, errors.Errorf("result item %q is nil", name)
 	}
-	bs, err := json.Marshal(msa)
-	if err != nil {
-		return err
+	t, ok = v.(T)
+	if !ok {
+		return t, errors.Errorf("result item %q: want %T, got %T", name, t, v)
 	}
-	err = json.Unmarshal(bs, v)
-	return err
+	return t, nil
 }
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResultGetters(t *testing.T) {
+	tpl, err := ParseTemplate("test", "{{name: string}} {{n: int}} {{f: float}} {{ok: bool}} {{at: time}};{{opt: int?}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval("foo 42 1.5 true 2024-01-01T10:00:00Z;", BuiltinFuncs())
+	errWhenNoneExpected(t, err)
+
+	assertEqual(t, []string{"name", "n", "f", "ok", "at", "opt"}, res.Names())
+	assertEqual(t, true, res.Has("n"))
+	assertEqual(t, false, res.Has("x"))
+	v, ok := res.Get("n")
+	assertEqual(t, true, ok)
+	assertEqual(t, 42, v)
+
+	name, err := res.String("name")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, "foo", name)
+	n, err := res.Int("n")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 42, n)
+	f, err := res.Float("f")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 1.5, f)
+	b, err := res.Bool("ok")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, true, b)
+	at, err := res.Time("at")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), at)
+	n, err = Value[int](res, "n")
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 42, n)
+
+	_, err = res.Int("x")
+	noErrWhenErrExpected(t, err)
+	_, err = res.Int("name")
+	noErrWhenErrExpected(t, err)
+	_, err = Value[float64](res, "n")
+	noErrWhenErrExpected(t, err)
+	_, err = res.Int("opt")
+	noErrWhenErrExpected(t, err)
+}
+
+func TestScanNamed(t *testing.T) {
+	tpl, err := ParseTemplate("test", "{{y: int}},{{x: int}},{{name: string}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval("2,1,foo", BuiltinFuncs())
+	errWhenNoneExpected(t, err)
+
+	var x, y int
+	var name string
+	errWhenNoneExpected(t, res.ScanNamed(map[string]any{"x": &x, "y": &y}))
+	assertEqual(t, 1, x)
+	assertEqual(t, 2, y)
+	noErrWhenErrExpected(t, res.ScanNamed(map[string]any{"x": &x, "y": &y}, RequireAll()))
+	noErrWhenErrExpected(t, res.ScanNamed(map[string]any{"z": &x}))
+	noErrWhenErrExpected(t, res.ScanNamed(map[string]any{"name": &x}))
+
+	x, y = 0, 0
+	errWhenNoneExpected(t, res.ScanInto("x", &x, "name", &name, "y", &y, RequireAll()))
+	assertEqual(t, 1, x)
+	assertEqual(t, 2, y)
+	assertEqual(t, "foo", name)
+	noErrWhenErrExpected(t, res.ScanInto("x", &x, "name", &name, RequireAll()))
+	noErrWhenErrExpected(t, res.ScanInto("x", &x, "y"))
+	noErrWhenErrExpected(t, res.ScanInto(&x, "x"))
+	noErrWhenErrExpected(t, res.ScanInto("x", &x, "x", &y))
+
+	var u8 uint8
+	err = res.Scan(&name)
+	noErrWhenErrExpected(t, err)
+	if !strings.Contains(err.Error(), `scan item "y"`) {
+		t.Fatalf("expect error to name the item, got %v", err)
+	}
+	res.Items[0].Value = 300
+	noErrWhenErrExpected(t, res.Scan(&u8))
+	noErrWhenErrExpected(t, res.Decode(&struct{ Y uint8 }{}))
+}
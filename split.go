@@ -0,0 +1,43 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+)
+
+const (
+	NUL             byte = 0x00
+	RecordSeparator byte = 0x1e
+)
+
+// ScanDelimited returns a split function, which splits the input at each occurrence of delim.
+func ScanDelimited(delim byte) bufio.SplitFunc {
+	return ScanSeparated([]byte{delim})
+}
+
+// ScanSeparated returns a split function, which splits the input at each occurrence of sep.
+func ScanSeparated(sep []byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		if i := bytes.Index(data, sep); i >= 0 {
+			return i + len(sep), data[:i], nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+var (
+	// ScanCRLF splits at "\r\n" only. Single "\r" or "\n" are part of the line.
+	ScanCRLF = ScanSeparated([]byte("\r\n"))
+	// ScanCR splits at "\r"
+	ScanCR = ScanDelimited('\r')
+	// ScanNUL splits at NUL bytes
+	ScanNUL = ScanDelimited(NUL)
+	// ScanRecords splits at ASCII record separators (0x1e)
+	ScanRecords = ScanDelimited(RecordSeparator)
+)
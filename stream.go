@@ -0,0 +1,163 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Found is a match of a template in a stream, decoded to T. Offset is the byte offset of the match in the stream
+// and Length is the number of matched bytes.
+type Found[T any] struct {
+	Value  T
+	Offset int64
+	Length int
+}
+
+// FindAll slides tpl over the whole input of r, across line boundaries, and returns all non-overlapping matches decoded to T.
+// A template starting with an evaler captures from the start of the line, one ending with an evaler up to the end
+// of the line. A candidate, which cannot be decided within MaxTokenSize bytes, fails with bufio.ErrTooLong.
+func FindAll[T any](tpl *Template, funcs Funcs, r io.Reader, opts ...Option) ([]Found[T], error) {
+	return FindAllContext[T](context.Background(), tpl, funcs, r, opts...)
+}
+
+// FindAllContext is like FindAll, but stops when ctx is done and passes ctx to context-aware funcs
+func FindAllContext[T any](ctx context.Context, tpl *Template, funcs Funcs, r io.Reader, opts ...Option) ([]Found[T], error) {
+	var fs []Found[T]
+	err := EachMatch(ctx, tpl, funcs, r, func(f Found[T]) error {
+		fs = append(fs, f)
+		return nil
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// EachMatch passes each match of tpl in r to fnc. Scanning stops at the first error returned by fnc.
+func EachMatch[T any](ctx context.Context, tpl *Template, funcs Funcs, r io.Reader, fnc func(f Found[T]) error, opts ...Option) error {
+	if len(tpl.items) == 0 {
+		return errors.Errorf("cannot find an empty template")
+	}
+	cfg := newConfig(opts...)
+	window := cfg.maxTokenSize
+
+	// leading and trailing evalers would capture across lines, so they are bound to their line:
+	// a leading evaler starts behind a newline and a trailing evaler ends in front of one
+	st := *tpl
+	st.items = tpl.Items()
+	leading := isCapture(st.items[0])
+	if leading {
+		st.items = append([]Item{"\n", boundToLine(st.items[0])}, st.items[1:]...)
+	}
+	trailing := isCapture(st.items[len(st.items)-1])
+	if trailing {
+		st.items = append(st.items, "\n")
+	}
+
+	// data holds the input starting at offset base and buf is a copy of it, which is rebuilt after advancing by window.
+	// The first line gets a virtual newline in front, which is not counted in offsets.
+	var data []byte
+	var base int64
+	if leading {
+		data = []byte("\n")
+		base = -1
+	}
+	var buf string
+	var pos int
+	var eof bool
+	chunk := make([]byte, window)
+	for {
+		if !eof && len(data)-pos <= window {
+			data = append(data[:0], data[pos:]...)
+			base += int64(pos)
+			pos = 0
+			for !eof && len(data) < 2*window {
+				if err := ctx.Err(); err != nil {
+					return errors.Wrapf(err, "cancelled at offset %d", base)
+				}
+				n, err := r.Read(chunk)
+				data = append(data, chunk[:n]...)
+				switch {
+				case err == io.EOF:
+					eof = true
+					if trailing && (len(data) == 0 || data[len(data)-1] != '\n') {
+						data = append(data, '\n')
+					}
+				case err != nil:
+					return errors.Wrapf(err, "read at offset %d", base+int64(len(data)))
+				}
+			}
+			buf = string(data)
+		}
+
+		// candidates closer than window to the end of buf are tried again, when more input has been read
+		to := len(buf)
+		if !eof {
+			to = len(buf) - window
+			for to > pos && !utf8.RuneStart(buf[to]) {
+				to--
+			}
+		}
+		if pos > to {
+			return nil
+		}
+		var incomplete int
+		m, err := st.search(ctx, buf, pos, to, funcs, &incomplete)
+		if !eof && incomplete >= 0 {
+			// the candidate has at least window bytes of input, but needs more to decide
+			return errors.Wrapf(bufio.ErrTooLong, "match at offset %d", base+int64(incomplete))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return errors.Wrapf(ctx.Err(), "cancelled at offset %d", base+int64(pos))
+			}
+			if eof {
+				return nil
+			}
+			// all candidates up to to are decided
+			_, size := utf8.DecodeRuneInString(buf[to:])
+			pos = to + size
+			continue
+		}
+
+		start, end := m.Start, m.End
+		if leading {
+			start++
+		}
+		if trailing {
+			end--
+		}
+		var t T
+		err = m.Result.Decode(&t)
+		if err != nil {
+			return errors.Wrapf(err, "decode match at offset %d", base+int64(start))
+		}
+		err = fnc(Found[T]{
+			Value:  t,
+			Offset: base + int64(start),
+			Length: end - start,
+		})
+		if err != nil {
+			return err
+		}
+
+		pos = m.End
+		if trailing {
+			// the newline may start the next match
+			pos--
+		}
+		if m.End == m.Start {
+			// never get stuck on an empty match
+			if pos >= len(buf) {
+				pos++
+			} else {
+				_, size := utf8.DecodeRuneInString(buf[pos:])
+				pos += size
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pkg/errors"
+)
+
+const mailText = `Hello,
+
+please contact <alice@example.com> (id: 12) or
+<bob@example.org> (id:
+7) for details. A broken entry <carol@example.net> (id: x) is skipped.
+
+Regards <dave@example.com> (id: 3)`
+
+type contact struct {
+	User   string `json:"user"`
+	Domain string `json:"domain"`
+	ID     int    `json:"id"`
+}
+
+func TestFindAllStream(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("contact", "<{{user: string}}@{{domain: string}}> (id:{{id: int}})")
+	errWhenNoneExpected(t, err)
+
+	exp := []contact{
+		{"alice", "example.com", 12},
+		{"bob", "example.org", 7},
+		{"dave", "example.com", 3},
+	}
+	for _, opts := range [][]Option{nil, {MaxTokenSize(64)}} {
+		fs, err := FindAll[contact](tpl, funcs, iotest.OneByteReader(bytes.NewBufferString(mailText)), opts...)
+		errWhenNoneExpected(t, err)
+		var have []contact
+		for _, f := range fs {
+			have = append(have, f.Value)
+			s := mailText[f.Offset : f.Offset+int64(f.Length)]
+			if !strings.HasPrefix(s, "<"+f.Value.User) || !strings.HasSuffix(s, ")") {
+				t.Fatalf("unexpected match %q at offset %d", s, f.Offset)
+			}
+		}
+		assertEqual(t, exp, have)
+	}
+}
+
+func TestFindAllStreamTooLong(t *testing.T) {
+	tpl, err := ParseTemplate("test", "[{{v: string}}]")
+	errWhenNoneExpected(t, err)
+	in := "x [short] y [a long value, which doesn't fit into the window] z"
+	_, err = FindAll[struct{ V string }](tpl, BuiltinFuncs(), bytes.NewBufferString(in), MaxTokenSize(8))
+	if !errors.Is(err, bufio.ErrTooLong) {
+		t.Fatalf("want %v, have %v", bufio.ErrTooLong, err)
+	}
+	fs, err := FindAll[struct{ V string }](tpl, BuiltinFuncs(), bytes.NewBufferString(in), MaxTokenSize(64))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []Found[struct{ V string }]{
+		{Value: struct{ V string }{"short"}, Offset: 2, Length: 7},
+		{Value: struct{ V string }{"a long value, which doesn't fit into the window"}, Offset: 12, Length: 49},
+	}, fs)
+}
+
+func TestFindAllStreamLeadingEvaler(t *testing.T) {
+	tpl, err := ParseTemplate("test", "{{user: string}}@{{domain: string}}>")
+	errWhenNoneExpected(t, err)
+	in := "hello world\nmail <alice@example.com>\nbob@example.org> and more\n"
+	for _, opts := range [][]Option{nil, {MaxTokenSize(32)}} {
+		fs, err := FindAll[contact](tpl, BuiltinFuncs(), iotest.OneByteReader(bytes.NewBufferString(in)), opts...)
+		errWhenNoneExpected(t, err)
+		assertEqual(t, []Found[contact]{
+			{Value: contact{User: "mail <alice", Domain: "example.com"}, Offset: 12, Length: 24},
+			{Value: contact{User: "bob", Domain: "example.org"}, Offset: 37, Length: 16},
+		}, fs)
+	}
+}
+
+func TestFindAllStreamSmallReads(t *testing.T) {
+	tpl, err := ParseTemplate("test", "<{{n: int}}>")
+	errWhenNoneExpected(t, err)
+	in := strings.Repeat("some text <1> and <22>\n", 10000)
+	fs, err := FindAll[struct{ N int }](tpl, BuiltinFuncs(), iotest.OneByteReader(bytes.NewBufferString(in)))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 20000, len(fs))
+}
+
+func TestFindAllStreamTrailingEvaler(t *testing.T) {
+	type entry struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("log", "level={{level: string}} msg={{msg: string}}")
+	errWhenNoneExpected(t, err)
+
+	in := "start\nlevel=info msg=up and running\nnoise level=warn msg=disk full"
+	fs, err := FindAll[entry](tpl, funcs, bytes.NewBufferString(in))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []Found[entry]{
+		{Value: entry{"info", "up and running"}, Offset: 6, Length: 29},
+		{Value: entry{"warn", "disk full"}, Offset: 42, Length: 24},
+	}, fs)
+}
+
+func TestFindAllStreamCancel(t *testing.T) {
+	tpl, err := ParseTemplate("contact", "<{{user: string}}@{{domain: string}}>")
+	errWhenNoneExpected(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = FindAllContext[contact](ctx, tpl, BuiltinFuncs(), bytes.NewBufferString(mailText))
+	noErrWhenErrExpected(t, err)
+}
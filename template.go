@@ -1,22 +1,120 @@
 package scan
 
 import (
+	"context"
 	"strings"
+	"unicode"
 
 	"github.com/pkg/errors"
 )
 
 type Item interface{}
 
+// Whitespace is an item, which matches one or more whitespace characters (space or tab). It is written as {{_}}.
+type Whitespace struct{}
+
+func isWhite(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// whiteLen returns the length of the whitespace prefix of s
+func whiteLen(s string) int {
+	idx := strings.IndexFunc(s, func(r rune) bool { return !isWhite(r) })
+	if idx < 0 {
+		return len(s)
+	}
+	return idx
+}
+
+func startsWithWhite(item Item) bool {
+	switch item := item.(type) {
+	case Whitespace:
+		return true
+	case string:
+		return item != "" && unicode.IsSpace(rune(item[0]))
+	}
+	return false
+}
+
+// Skip is an item, which consumes text like an evaler, but neither calls a func nor produces a result item.
+// It is written as {{-}} or {{-: <func>}}. The wildcard {{*}} swallows everything up to the next literal.
+// Both match empty text.
+type Skip struct {
+	Wildcard bool
+	funcName string
+	// line bounds the skipped text to the end of the line (see EachMatch)
+	line bool
+}
+
+func (s Skip) spec() string {
+	switch {
+	case s.Wildcard:
+		return "*"
+	case s.funcName != "":
+		return "-: " + s.funcName
+	default:
+		return "-"
+	}
+}
+
+// isCapture reports whether item captures the text up to the next item
+func isCapture(item Item) bool {
+	switch item.(type) {
+	case Evaler, Skip:
+		return true
+	}
+	return false
+}
+
+// acceptsEmpty reports whether item matches at the end of the input
+func acceptsEmpty(item Item) bool {
+	switch item := item.(type) {
+	case Evaler:
+		return item.optional()
+	case Skip:
+		return true
+	}
+	return false
+}
+
 type Template struct {
-	name  string
-	items []Item
+	name     string
+	typeName string
+	items    []Item
+	opts     ParseOptions
 }
 
 func (t *Template) Name() string {
 	return t.name
 }
 
+// TypeName is the name of the type the results of the template are meant to be decoded into, if given in a template file
+func (t *Template) TypeName() string {
+	return t.typeName
+}
+
+func (t *Template) Options() ParseOptions {
+	return t.opts
+}
+
+// String returns the canonical pattern text of the template, which parses to the same items
+func (t *Template) String() string {
+	var sb strings.Builder
+	for _, item := range t.items {
+		switch item := item.(type) {
+		case string:
+			sb.WriteString(escapeLiteral(item, t.opts))
+		case Whitespace:
+			sb.WriteString(t.opts.left() + "_" + t.opts.right())
+		case Evaler:
+			sb.WriteString(t.opts.left() + item.spec() + t.opts.right())
+		case Skip:
+			sb.WriteString(t.opts.left() + item.spec() + t.opts.right())
+		}
+	}
+	return sb.String()
+}
+
 func (t *Template) Prefix() string {
 	if len(t.items) == 0 {
 		return ""
@@ -27,15 +125,127 @@ func (t *Template) Prefix() string {
 	return ""
 }
 
-//
+// Items returns the parsed items of the template, which are literal strings, Whitespace, Skip or Evalers
+func (t *Template) Items() []Item {
+	return append([]Item{}, t.items...)
+}
+
+// Evalers returns the evalers of the template in order of their appearance
+func (t *Template) Evalers() []Evaler {
+	var evs []Evaler
+	for _, item := range t.items {
+		if ev, ok := item.(Evaler); ok {
+			evs = append(evs, ev)
+		}
+	}
+	return evs
+}
+
 func (t *Template) Eval(s string, funcs Funcs) (*Result, error) {
-	res := &Result{
+	return t.EvalContext(context.Background(), s, funcs)
+}
+
+// EvalContext is like Eval, but passes ctx to context-aware funcs
+func (t *Template) EvalContext(ctx context.Context, s string, funcs Funcs) (*Result, error) {
+	res, _, _, err := t.evalAt(ctx, t.input(s), 0, funcs, nil)
+	return res, err
+}
+
+// EvalTrace is like Eval, but additionally returns a trace of how each item was matched
+func (t *Template) EvalTrace(s string, funcs Funcs) (*Result, *Trace, error) {
+	tr := &Trace{
+		Input: t.input(s),
+	}
+	res, _, _, err := t.evalAt(context.Background(), tr.Input, 0, funcs, tr)
+	tr.Err = err
+	return res, tr, err
+}
+
+// input prepares s for evaluation. Only strict templates keep surrounding whitespace.
+func (t *Template) input(s string) string {
+	in, _ := t.inputOffset(s)
+	return in
+}
+
+// inputOffset is like input, but additionally returns the offset of the prepared input in s
+func (t *Template) inputOffset(s string) (string, int) {
+	if t.opts.StrictLiterals {
+		return s, 0
+	}
+	trimmed := strings.TrimLeftFunc(s, unicode.IsSpace)
+	return strings.TrimRightFunc(trimmed, unicode.IsSpace), len(s) - len(trimmed)
+}
+
+// endOfInput marks errors, which occur because the input ends. With more input, the template might match.
+type endOfInput struct {
+	error
+}
+
+func isEndOfInput(err error) bool {
+	var e endOfInput
+	return errors.As(err, &e)
+}
+
+// boundToLine returns the capture item with its capture bounded to the end of the line
+func boundToLine(item Item) Item {
+	switch item := item.(type) {
+	case Evaler:
+		item.line = true
+		return item
+	case Skip:
+		item.line = true
+		return item
+	default:
+		return item
+	}
+}
+
+// lineOf returns s up to the end of the line containing pos, if bound is set. Otherwise s is returned.
+func lineOf(s string, pos int, bound bool) string {
+	if !bound {
+		return s
+	}
+	if idx := strings.IndexByte(s[pos:], '\n'); idx >= 0 {
+		return s[:pos+idx]
+	}
+	return s
+}
+
+// findSeparator returns the position and length of the next occurrence of the separator item in s, starting at pos
+func findSeparator(s string, pos int, sep Item) (int, int, error) {
+	switch sep := sep.(type) {
+	case string:
+		idx := strings.Index(s[pos:], sep)
+		if idx < 0 {
+			return -1, 0, endOfInput{errors.Errorf("no match for next %q", sep)}
+		}
+		return pos + idx, len(sep), nil
+	case Whitespace:
+		idx := strings.IndexFunc(s[pos:], isWhite)
+		if idx < 0 {
+			return -1, 0, endOfInput{errors.Errorf("no match for next whitespace")}
+		}
+		return pos + idx, whiteLen(s[pos+idx:]), nil
+	default:
+		return -1, 0, errors.Errorf("next is not a string")
+	}
+}
+
+// evalAt evaluates the prepared input s starting at start. It returns the result and the positions
+// where the first item begins and where the last item ends.
+func (t *Template) evalAt(ctx context.Context, s string, start int, funcs Funcs, tr *Trace) (res *Result, begin int, end int, err error) {
+	res = &Result{
 		//Items: map[string]any{},
 	}
-	s = strings.TrimSpace(s)
-	var pos int = 0
+	strict := t.opts.StrictLiterals
+	var pos int = start
+	begin = -1
 
-	eatWhite := func() {
+	// eatWhite skips spaces in front of an item, unless the template is strict or the item matches whitespace itself
+	eatWhite := func(item Item) {
+		if strict || startsWithWhite(item) {
+			return
+		}
 		for pos < len(s) {
 			if s[pos] != ' ' {
 				return
@@ -45,43 +255,95 @@ func (t *Template) Eval(s string, funcs Funcs) (*Result, error) {
 	}
 
 	for i, item := range t.items {
-		eatWhite()
-		if pos >= len(s) {
-			return nil, errors.Errorf("EOF")
+		eatWhite(item)
+		if begin < 0 {
+			begin = pos
+		}
+		if pos >= len(s) && !acceptsEmpty(item) {
+			err := endOfInput{errors.Errorf("EOF")}
+			tr.add(TraceStep{Item: item, Start: pos, End: pos, Split: -1, Err: err})
+			return nil, 0, 0, err
 		}
 		switch item := item.(type) {
 		case string:
 			if !strings.HasPrefix(s[pos:], item) {
-				return nil, errors.Errorf("no match for string %q", item)
+				var err error = errors.Errorf("no match for string %q", item)
+				if strings.HasPrefix(item, s[pos:]) {
+					err = endOfInput{err}
+				}
+				tr.add(TraceStep{Item: item, Start: pos, End: pos, Split: -1, Err: err})
+				return nil, 0, 0, err
 			}
+			tr.add(TraceStep{Item: item, Start: pos, End: pos + len(item), Split: -1, Text: item})
 			pos += len(item)
+		case Whitespace:
+			n := whiteLen(s[pos:])
+			if n == 0 {
+				err := errors.Errorf("no match for whitespace")
+				tr.add(TraceStep{Item: item, Start: pos, End: pos, Split: -1, Err: err})
+				return nil, 0, 0, err
+			}
+			tr.add(TraceStep{Item: item, Start: pos, End: pos + n, Split: -1, Text: s[pos : pos+n]})
+			pos += n
 		case Evaler:
-			var es string
-			if i == len(t.items)-1 {
-				es = s[pos:]
-			} else {
-				//peek next string
-				next, ok := t.items[i+1].(string)
-				if !ok {
-					return nil, errors.Errorf("next is not a string")
+			ls := lineOf(s, pos, item.line)
+			split := len(ls)
+			if n, ok := item.capture(s[pos:], funcs); ok {
+				split = pos + n
+			} else if i < len(t.items)-1 {
+				var err error
+				if item.balanced(funcs) {
+					split, _, err = findBalancedSeparator(ls, pos, t.items[i+1])
+				} else {
+					split, _, err = findSeparator(ls, pos, t.items[i+1])
+				}
+				if err != nil && len(ls) < len(s) {
+					err = errors.Errorf("no match for next %v in line", t.items[i+1])
 				}
-				nextIdx := strings.Index(s[pos:], next)
-				if nextIdx < 0 {
-					return nil, errors.Errorf("no match for next %q", next)
+				if err != nil {
+					tr.add(TraceStep{Item: item, Start: pos, End: pos, Split: -1, Func: item.funcName, Err: err})
+					return nil, 0, 0, err
 				}
-				es = s[pos : pos+nextIdx]
 			}
-			es = strings.TrimSpace(es)
+			es := s[pos:split]
+			if !strict {
+				trimmed := strings.TrimLeftFunc(es, unicode.IsSpace)
+				pos += len(es) - len(trimmed)
+				es = strings.TrimRightFunc(trimmed, unicode.IsSpace)
+			}
 
-			v, err := item.Eval(es, funcs)
+			v, err := item.EvalContext(ctx, es, funcs)
+			if err != nil {
+				v = nil
+			}
+			tr.add(TraceStep{Item: item, Start: pos, End: pos + len(es), Split: split, Text: es, Func: item.funcName, Value: v, Err: err})
 			if err != nil {
-				return nil, errors.Wrapf(err, "eval %q", es)
+				return nil, 0, 0, errors.Wrapf(err, "eval %q", es)
 			}
 
 			res.Items = append(res.Items, ResultItem{item.name, v})
 			pos += len(es)
+		case Skip:
+			ls := lineOf(s, pos, item.line)
+			split := len(ls)
+			if i < len(t.items)-1 {
+				var err error
+				split, _, err = findSeparator(ls, pos, t.items[i+1])
+				if err != nil && len(ls) < len(s) {
+					err = errors.Errorf("no match for next %v in line", t.items[i+1])
+				}
+				if err != nil {
+					tr.add(TraceStep{Item: item, Start: pos, End: pos, Split: -1, Err: err})
+					return nil, 0, 0, err
+				}
+			}
+			tr.add(TraceStep{Item: item, Start: pos, End: split, Split: split, Text: s[pos:split]})
+			pos = split
 		}
 	}
+	if begin < 0 {
+		begin = start
+	}
 
-	return res, nil
+	return res, begin, pos, nil
 }
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strings"
@@ -464,6 +465,61 @@ func TestDecode(t *testing.T) {
 			arg:      ptr[pair](),
 			exparg:   &pair{1, 2},
 		},
+		{
+			template: "{{name: string}}: {{p.first: int}}:{{p.second: int}}",
+			in:       "foo: 1:2",
+			arg: ptr[struct {
+				Name string
+				P    *pair
+			}](),
+			exparg: &struct {
+				Name string
+				P    *pair
+			}{"foo", &pair{1, 2}},
+		},
+		{
+			template: "{{tags[]: string}}, {{tags[]: string}}, {{nums[]: int}}",
+			in:       "a, b, 3",
+			arg: ptr[struct {
+				Tags []string
+				Nums []float64
+			}](),
+			exparg: &struct {
+				Tags []string
+				Nums []float64
+			}{[]string{"a", "b"}, []float64{3}},
+		},
+		{
+			template: "{{counts.a: int}}, {{counts.b: int}}",
+			in:       "1, 2",
+			arg: ptr[struct {
+				Counts map[string]int `json:"counts"`
+			}](),
+			exparg: &struct {
+				Counts map[string]int `json:"counts"`
+			}{map[string]int{"a": 1, "b": 2}},
+		},
+		{
+			template: "{{p.first: int}}, {{p.second: int}}, {{tags[]: string}}",
+			in:       "1, 2, a",
+			arg:      &map[string]any{},
+			exparg: &map[string]any{
+				"p":    map[string]any{"first": 1, "second": 2},
+				"tags": []any{"a"},
+			},
+		},
+		{
+			template: "{{first.x: int}}",
+			in:       "1",
+			arg:      ptr[pair](),
+			fail:     true,
+		},
+		{
+			template: "{{first[]: int}}",
+			in:       "1",
+			arg:      ptr[pair](),
+			fail:     true,
+		},
 	}
 	for i, test := range tests {
 		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
@@ -491,3 +547,87 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+type testLevel int
+
+func (l *testLevel) UnmarshalJSON(bs []byte) error {
+	var s string
+	if err := json.Unmarshal(bs, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "info":
+		*l = 1
+	case "warn":
+		*l = 2
+	default:
+		return fmt.Errorf("invalid level %q", s)
+	}
+	return nil
+}
+
+type testUpper string
+
+func (u *testUpper) UnmarshalText(bs []byte) error {
+	*u = testUpper(strings.ToUpper(string(bs)))
+	return nil
+}
+
+func TestDecodeUnmarshalers(t *testing.T) {
+	type target struct {
+		Level  testLevel
+		PLevel *testLevel
+		Name   testUpper
+		PName  *testUpper
+	}
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{level: string}} {{plevel: string}} {{name: string}} {{pname: string}}")
+	errWhenNoneExpected(t, err)
+	res, err := tpl.Eval("warn info bob alice", funcs)
+	errWhenNoneExpected(t, err)
+	var have target
+	errWhenNoneExpected(t, res.Decode(&have))
+	assertEqual(t, target{Level: 2, PLevel: ptrVal(testLevel(1)), Name: "BOB", PName: ptrVal(testUpper("ALICE"))}, have)
+
+	res, err = tpl.Eval("debug info bob alice", funcs)
+	errWhenNoneExpected(t, err)
+	noErrWhenErrExpected(t, res.Decode(&have))
+}
+
+func TestDecodeFieldNames(t *testing.T) {
+	type target struct {
+		Name  string `json:"name"`
+		Level int    `json:"lvl,omitempty"`
+		Mode  string
+		Both  string `json:"both"`
+		BOTH  string
+		Skip  string `json:"-"`
+	}
+	// decodes the items like encoding/json does
+	jsonDecode := func(res *Result, v any) error {
+		m := map[string]any{}
+		for _, item := range res.Items {
+			m[item.Name] = item.Value
+		}
+		bs, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal(bs, v)
+	}
+
+	funcs := BuiltinFuncs()
+	for _, pattern := range []string{
+		"{{Name: string}} {{LVL: int}} {{mode: string}} {{both: string}} {{BOTH: string}} {{skip: string}}",
+		"{{name: string}} {{lvl: int}} {{MODE: string}} {{Both: string}} {{bOTH: string}} {{Skip: string}}",
+	} {
+		tpl, err := ParseTemplate("test", pattern)
+		errWhenNoneExpected(t, err)
+		res, err := tpl.Eval("bob 3 fast b1 b2 x", funcs)
+		errWhenNoneExpected(t, err)
+		var want, have target
+		errWhenNoneExpected(t, jsonDecode(res, &want))
+		errWhenNoneExpected(t, res.Decode(&have))
+		assertEqual(t, want, have)
+	}
+}
@@ -0,0 +1,178 @@
+package scan
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FuncDecl declares a named func in a template file, which is created by a builtin factory
+type FuncDecl struct {
+	Name    string
+	Factory string
+	Args    []string
+}
+
+// TemplateSet is a set of named templates as loaded from a template file.
+//
+// A template file consists of lines in the form
+//
+//	# comment
+//	func <name> = <factory>(<arg>, ...)
+//	template <name> [<type-name>] = <pattern>
+//
+// Args are either bare or quoted in Go syntax. Templates are tried in the order of their declaration.
+type TemplateSet struct {
+	// Funcs contains the builtin funcs, the funcs passed in LoadOptions and the declared funcs
+	Funcs     Funcs
+	Decls     []FuncDecl
+	Templates []*Template
+}
+
+// LoadOptions configure LoadTemplatesWith
+type LoadOptions struct {
+	// Funcs are available to the templates in addition to the builtin and the declared funcs
+	Funcs Funcs
+	// ParseOptions are used to parse the patterns of the templates
+	ParseOptions ParseOptions
+}
+
+func LoadTemplates(r io.Reader) (*TemplateSet, error) {
+	return LoadTemplatesWith(r, LoadOptions{})
+}
+
+// LoadTemplatesWith is like LoadTemplates, but templates may use the funcs in opts and are parsed with its ParseOptions.
+// Lines are not limited in size.
+func LoadTemplatesWith(r io.Reader, opts LoadOptions) (*TemplateSet, error) {
+	ts := &TemplateSet{
+		Funcs: BuiltinFuncs(),
+	}
+	ts.Funcs.AddFuncs(opts.Funcs)
+	factories := BuiltinFactories()
+	declared := map[string]bool{}
+
+	var lineNo int
+	br := bufio.NewReader(r)
+	for {
+		text, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrapf(err, "line %d", lineNo+1)
+		}
+		if err == io.EOF && text == "" {
+			break
+		}
+		lineNo++
+		ln := strings.TrimSpace(text)
+		if ln == "" || strings.HasPrefix(ln, "#") {
+			continue
+		}
+		kind, rest, _ := strings.Cut(ln, " ")
+		head, body, ok := strings.Cut(rest, "=")
+		if !ok {
+			return nil, errors.Errorf("line %d: invalid syntax. missing =", lineNo)
+		}
+		fields := strings.Fields(head)
+		body = strings.TrimSpace(body)
+		switch kind {
+		case "func":
+			if len(fields) != 1 {
+				return nil, errors.Errorf("line %d: invalid syntax. want func <name> = <factory>(<args>)", lineNo)
+			}
+			name := fields[0]
+			if declared[name] {
+				return nil, errors.Errorf("line %d: func %q already declared", lineNo, name)
+			}
+			factoryName, args, err := parseCall(body)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", lineNo)
+			}
+			factory, ok := factories[factoryName]
+			if !ok {
+				return nil, errors.Errorf("line %d: no such factory %q", lineNo, factoryName)
+			}
+			fnc, err := factory(args...)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d: create func %q", lineNo, name)
+			}
+			declared[name] = true
+			ts.Funcs.AddFunc(name, fnc)
+			ts.Decls = append(ts.Decls, FuncDecl{
+				Name:    name,
+				Factory: factoryName,
+				Args:    args,
+			})
+		case "template":
+			if len(fields) < 1 || len(fields) > 2 {
+				return nil, errors.Errorf("line %d: invalid syntax. want template <name> [<type-name>] = <pattern>", lineNo)
+			}
+			name := fields[0]
+			if _, ok := ts.Lookup(name); ok {
+				return nil, errors.Errorf("line %d: template %q already declared", lineNo, name)
+			}
+			tpl, err := ParseTemplateWith(name, body, opts.ParseOptions)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d: parse template %q", lineNo, name)
+			}
+			if len(fields) == 2 {
+				tpl.typeName = fields[1]
+			}
+			ts.Templates = append(ts.Templates, tpl)
+		default:
+			return nil, errors.Errorf("line %d: unknown declaration %q", lineNo, kind)
+		}
+	}
+
+	for _, tpl := range ts.Templates {
+		for _, ev := range tpl.Evalers() {
+			if err := ev.checkFuncs(ts.Funcs); err != nil {
+				return nil, errors.Wrapf(err, "template %q", tpl.name)
+			}
+		}
+	}
+	return ts, nil
+}
+
+func (ts *TemplateSet) Lookup(name string) (*Template, bool) {
+	for _, tpl := range ts.Templates {
+		if tpl.name == name {
+			return tpl, true
+		}
+	}
+	return nil, false
+}
+
+// Eval evaluates s with the templates in order and returns the result of the first one that matches
+func (ts *TemplateSet) Eval(s string) (*Template, *Result, error) {
+	if len(ts.Templates) == 0 {
+		return nil, nil, errors.Errorf("no templates")
+	}
+	var errs []string
+	for _, tpl := range ts.Templates {
+		res, err := tpl.Eval(s, ts.Funcs)
+		if err == nil {
+			return tpl, res, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %v", tpl.name, err))
+	}
+	return nil, nil, errors.Errorf("no template matches: %s", strings.Join(errs, "; "))
+}
+
+// WriteTo writes the set in the template file format
+func (ts *TemplateSet) WriteTo(w io.Writer) (int64, error) {
+	var sb strings.Builder
+	for _, decl := range ts.Decls {
+		fmt.Fprintf(&sb, "func %s = %s\n", decl.Name, formatCall(decl.Factory, decl.Args))
+	}
+	for _, tpl := range ts.Templates {
+		if tpl.typeName != "" {
+			fmt.Fprintf(&sb, "template %s %s = %s\n", tpl.name, tpl.typeName, tpl.String())
+		} else {
+			fmt.Fprintf(&sb, "template %s = %s\n", tpl.name, tpl.String())
+		}
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
@@ -0,0 +1,138 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+const templateFile = `
+# reactor reboot steps
+func date = time("2006-01-02")
+func state = enum(on, off)
+func hex = int(16)
+
+template cmd Command = {{action: state}} x={{x0: int}}..{{x1: int}}
+template dated = {{day: date}}: {{mask: hex}}
+template any   = {{text: string}}
+`
+
+func TestLoadTemplates(t *testing.T) {
+	ts, err := LoadTemplates(bytes.NewBufferString(templateFile))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 3, len(ts.Templates))
+	assertEqual(t, []FuncDecl{
+		{"date", "time", []string{"2006-01-02"}},
+		{"state", "enum", []string{"on", "off"}},
+		{"hex", "int", []string{"16"}},
+	}, ts.Decls)
+
+	cmd, ok := ts.Lookup("cmd")
+	if !ok {
+		t.Fatalf("expect template cmd")
+	}
+	assertEqual(t, "Command", cmd.TypeName())
+	assertEqual(t, "{{action: state}}x={{x0: int}}..{{x1: int}}", cmd.String())
+
+	tests := []struct {
+		in     string
+		expTpl string
+		params []ResultItem
+	}{
+		{
+			in:     "on x=1..2",
+			expTpl: "cmd",
+			params: []ResultItem{{"action", "on"}, {"x0", 1}, {"x1", 2}},
+		},
+		{
+			in:     "2022-12-22: ff",
+			expTpl: "dated",
+			params: []ResultItem{{"day", time.Date(2022, 12, 22, 0, 0, 0, 0, time.UTC)}, {"mask", 255}},
+		},
+		{
+			in:     "toggle x=1..2",
+			expTpl: "any",
+			params: []ResultItem{{"text", "toggle x=1..2"}},
+		},
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			tpl, res, err := ts.Eval(test.in)
+			errWhenNoneExpected(t, err)
+			assertEqual(t, test.expTpl, tpl.Name())
+			assertEqual(t, test.params, res.Items)
+		})
+	}
+
+	// round trip
+	buf := &bytes.Buffer{}
+	_, err = ts.WriteTo(buf)
+	errWhenNoneExpected(t, err)
+	ts2, err := LoadTemplates(buf)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, ts.Decls, ts2.Decls)
+	assertEqual(t, ts.Templates, ts2.Templates)
+}
+
+func TestLoadTemplatesFail(t *testing.T) {
+	tests := []string{
+		"func date time(\"2006\")",
+		"func date = nofactory(1)",
+		"func date = time()",
+		"func hex = int(16)\nfunc hex = int(8)",
+		"template a = {{x: int}}\ntemplate a = {{y: int}}",
+		"template a = {{x: nofunc}}",
+		"template a b c = {{x: int}}",
+		"tpl a = {{x: int}}",
+		"template a = {{x: int}",
+	}
+	for i, test := range tests {
+		t.Run(fmt.Sprintf("test #%02d", i), func(t *testing.T) {
+			_, err := LoadTemplates(bytes.NewBufferString(test))
+			noErrWhenErrExpected(t, err)
+		})
+	}
+}
+
+func TestLoadTemplatesWith(t *testing.T) {
+	funcs := Funcs{}
+	funcs.Add("shout", func(s string) (any, error) {
+		return strings.ToUpper(s), nil
+	})
+	file := "func hex = int(16)\ntemplate a = <name: shout> <mask: hex> <text: string>\n"
+	popts := ParseOptions{LeftDelim: "<", RightDelim: ">"}
+	_, err := LoadTemplatesWith(bytes.NewBufferString(file), LoadOptions{ParseOptions: popts})
+	noErrWhenErrExpected(t, err)
+
+	ts, err := LoadTemplatesWith(bytes.NewBufferString(file), LoadOptions{
+		Funcs:        funcs,
+		ParseOptions: popts,
+	})
+	errWhenNoneExpected(t, err)
+	_, res, err := ts.Eval("bob ff " + strings.Repeat("x", 2*bufio.MaxScanTokenSize))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"name", "BOB"}, {"mask", 255}, {"text", strings.Repeat("x", 2*bufio.MaxScanTokenSize)}}, res.Items)
+
+	long := "template b = {{text: string}} " + strings.Repeat("y", 2*bufio.MaxScanTokenSize)
+	ts, err = LoadTemplates(bytes.NewBufferString(long))
+	errWhenNoneExpected(t, err)
+	assertEqual(t, 1, len(ts.Templates))
+}
+
+func TestTemplateString(t *testing.T) {
+	for _, pattern := range []string{
+		"",
+		"no evalers",
+		"{{a: int}}",
+		"a {{a: int}}, b {{b: []string}} and {{c: float}}.",
+	} {
+		tpl, err := ParseTemplate("test", pattern)
+		errWhenNoneExpected(t, err)
+		tpl2, err := ParseTemplate("test", tpl.String())
+		errWhenNoneExpected(t, err)
+		assertEqual(t, tpl.items, tpl2.items)
+	}
+}
@@ -0,0 +1,136 @@
+package scan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TraceStep records how a single template item was matched against the input
+type TraceStep struct {
+	Item Item
+	// Start and End are byte offsets of the matched literal or the captured substring in Trace.Input
+	Start int
+	End   int
+	// Split is the position where the input was split for an evaler, which is the position of the next literal or the end of the input.
+	// It is -1 for literals.
+	Split int
+	Text  string
+	Func  string
+	Value any
+	Err   error
+}
+
+// Trace is the record of a template evaluation
+type Trace struct {
+	Input string
+	Steps []TraceStep
+	Err   error
+}
+
+func (tr *Trace) add(step TraceStep) {
+	if tr == nil {
+		return
+	}
+	tr.Steps = append(tr.Steps, step)
+}
+
+const (
+	colorReset = "\x1b[0m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorGray  = "\x1b[90m"
+)
+
+// Render writes an annotated view of the input line, which marks the part of the input each item matched.
+// If color is true, literals, captures and errors are highlighted using ANSI escape codes.
+func (tr *Trace) Render(w io.Writer, color bool) error {
+	paint := func(c string, s string) string {
+		if !color || s == "" {
+			return s
+		}
+		return c + s + colorReset
+	}
+	stepColor := func(step TraceStep) string {
+		switch {
+		case step.Err != nil:
+			return colorRed
+		case isLiteral(step.Item):
+			return colorGreen
+		default:
+			return colorCyan
+		}
+	}
+
+	// the input line with matched parts coloured
+	var sb strings.Builder
+	var pos int
+	for _, step := range tr.Steps {
+		if step.Start < pos || step.End > len(tr.Input) {
+			continue
+		}
+		sb.WriteString(paint(colorGray, tr.Input[pos:step.Start]))
+		sb.WriteString(paint(stepColor(step), tr.Input[step.Start:step.End]))
+		pos = step.End
+	}
+	sb.WriteString(paint(colorGray, tr.Input[pos:]))
+	sb.WriteString("\n")
+
+	// one annotation line per step
+	for _, step := range tr.Steps {
+		col := utf8.RuneCountInString(tr.Input[:step.Start])
+		width := utf8.RuneCountInString(tr.Input[step.Start:step.End])
+		marker := "^"
+		if width > 1 {
+			marker += strings.Repeat("~", width-1)
+		}
+		sb.WriteString(strings.Repeat(" ", col))
+		sb.WriteString(paint(stepColor(step), marker+" "+describeStep(step)))
+		sb.WriteString("\n")
+	}
+	if tr.Err != nil {
+		sb.WriteString(paint(colorRed, fmt.Sprintf("error: %v", tr.Err)))
+		sb.WriteString("\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (tr *Trace) String() string {
+	var sb strings.Builder
+	tr.Render(&sb, false)
+	return sb.String()
+}
+
+func isLiteral(item Item) bool {
+	_, ok := item.(string)
+	return ok
+}
+
+func describeStep(step TraceStep) string {
+	var desc string
+	switch item := step.Item.(type) {
+	case string:
+		desc = fmt.Sprintf("literal %q", item)
+	case Whitespace:
+		desc = fmt.Sprintf("whitespace %q", step.Text)
+	case Skip:
+		desc = fmt.Sprintf("skip %s %q", item.spec(), step.Text)
+	case Evaler:
+		desc = fmt.Sprintf("%s: %s(%q)", item.name, step.Func, step.Text)
+		if step.Split >= 0 {
+			desc += fmt.Sprintf(" split at %d", step.Split)
+		}
+		if step.Err == nil {
+			desc += fmt.Sprintf(" => %#v", step.Value)
+		}
+	default:
+		desc = fmt.Sprintf("%v", item)
+	}
+	if step.Err != nil {
+		desc += fmt.Sprintf(" failed: %v", step.Err)
+	}
+	return desc
+}
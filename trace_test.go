@@ -0,0 +1,47 @@
+package scan
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEvalTrace(t *testing.T) {
+	funcs := BuiltinFuncs()
+	tpl, err := ParseTemplate("test", "{{action: string}} x={{x0: int}}..{{x1: int}}")
+	errWhenNoneExpected(t, err)
+
+	res, tr, err := tpl.EvalTrace("on x=-46..2", funcs)
+	errWhenNoneExpected(t, err)
+	assertEqual(t, []ResultItem{{"action", "on"}, {"x0", -46}, {"x1", 2}}, res.Items)
+	assertEqual(t, []TraceStep{
+		{Item: tpl.items[0], Start: 0, End: 2, Split: 3, Text: "on", Func: "string", Value: "on"},
+		{Item: "x=", Start: 3, End: 5, Split: -1, Text: "x="},
+		{Item: tpl.items[2], Start: 5, End: 8, Split: 8, Text: "-46", Func: "int", Value: -46},
+		{Item: "..", Start: 8, End: 10, Split: -1, Text: ".."},
+		{Item: tpl.items[4], Start: 10, End: 11, Split: 11, Text: "2", Func: "int", Value: 2},
+	}, tr.Steps)
+
+	expect := strings.Join([]string{
+		"on x=-46..2",
+		`^~ action: string("on") split at 3 => "on"`,
+		`   ^~ literal "x="`,
+		`     ^~~ x0: int("-46") split at 8 => -46`,
+		`        ^~ literal ".."`,
+		`          ^ x1: int("2") split at 11 => 2`,
+		"",
+	}, "\n")
+	assertEqual(t, expect, tr.String())
+
+	_, tr, err = tpl.EvalTrace("on x=-46..boom", funcs)
+	noErrWhenErrExpected(t, err)
+	if len(tr.Steps) != 5 || tr.Steps[4].Err == nil || tr.Err == nil {
+		t.Fatalf("expect failing last step, have %v", tr.Steps)
+	}
+
+	sb := &strings.Builder{}
+	err = tr.Render(sb, true)
+	errWhenNoneExpected(t, err)
+	if !strings.Contains(sb.String(), colorRed) {
+		t.Fatalf("expect red error marker in %q", sb.String())
+	}
+}